@@ -7,12 +7,15 @@ import (
 )
 
 type EmergencyFilterConfig struct {
-	Enabled      bool          `toml:"enabled"`
-	NewKeysRate  float64       `toml:"new_keys_rate"`
-	NewKeysBurst int           `toml:"new_keys_burst"`
-	CacheSize    int           `toml:"cache_size"`
-	TTL          time.Duration `toml:"ttl"`
-	PerIP        struct {
+	Enabled             bool          `toml:"enabled"`
+	DryRun              bool          `toml:"dry_run"`
+	NewKeysRate         float64       `toml:"new_keys_rate"`
+	NewKeysBurst        int           `toml:"new_keys_burst"`
+	CacheSize           int           `toml:"cache_size"`
+	TTL                 time.Duration `toml:"ttl"`
+	PoWBypassDifficulty int           `toml:"pow_bypass_difficulty"`
+	EarlyDropStart      float64       `toml:"early_drop_start"`
+	PerIP               struct {
 		Enabled    bool          `toml:"enabled"`
 		Rate       float64       `toml:"rate"`
 		Burst      int           `toml:"burst"`
@@ -26,40 +29,55 @@ type EmergencyFilterConfig struct {
 type RateLimiterBy string
 
 const (
-	RateByIP     RateLimiterBy = "ip"
-	RateByPubKey RateLimiterBy = "pubkey"
-	RateByBoth   RateLimiterBy = "both"
+	RateByIP       RateLimiterBy = "ip"
+	RateByPubKey   RateLimiterBy = "pubkey"
+	RateByBoth     RateLimiterBy = "both"
+	RateByTagValue RateLimiterBy = "tag_value"
 )
 
 func (m *RateLimiterBy) UnmarshalText(text []byte) error {
 	v := string(text)
 	switch RateLimiterBy(v) {
-	case RateByIP, RateByPubKey, RateByBoth, "":
+	case RateByIP, RateByPubKey, RateByBoth, RateByTagValue, "":
 		*m = RateLimiterBy(v)
 		return nil
 	default:
-		return fmt.Errorf("invalid rate_limiter.by: %q (must be ip, pubkey, both)", v)
+		return fmt.Errorf("invalid rate_limiter.by: %q (must be ip, pubkey, both, tag_value)", v)
 	}
 }
 
 type RateLimitRule struct {
-	Description string  `toml:"description"`
-	Kinds       []int   `toml:"kinds"`
-	Rate        float64 `toml:"rate"`
-	Burst       int     `toml:"burst"`
+	Description   string   `toml:"description"`
+	Kinds         []int    `toml:"kinds"`
+	Rate          float64  `toml:"rate"`
+	Burst         int      `toml:"burst"`
+	Cost          int      `toml:"cost"`
+	ExemptPubkeys []string `toml:"exempt_pubkeys"`
+	PerTarget     bool     `toml:"per_target"`
+	TargetRate    float64  `toml:"target_rate"`
+	TargetBurst   int      `toml:"target_burst"`
 }
 
 type RateLimiterConfig struct {
-	Enabled      bool            `toml:"enabled"`
-	By           RateLimiterBy   `toml:"by"`
-	CacheSize    int             `toml:"cache_size"`
-	TTL          time.Duration   `toml:"ttl"`
-	DefaultRate  float64         `toml:"default_rate"`
-	DefaultBurst int             `toml:"default_burst"`
-	Rules        []RateLimitRule `toml:"rule"`
+	Enabled         bool              `toml:"enabled"`
+	DryRun          bool              `toml:"dry_run"`
+	By              RateLimiterBy     `toml:"by"`
+	TagName         string            `toml:"tag_name"`
+	CacheSize       int               `toml:"cache_size"`
+	TTL             time.Duration     `toml:"ttl"`
+	DefaultRate     float64           `toml:"default_rate"`
+	DefaultBurst    int               `toml:"default_burst"`
+	FailOpen        bool              `toml:"fail_open"`
+	ExemptPubkeys   []string          `toml:"exempt_pubkeys"`
+	QuietMultiplier float64           `toml:"quiet_multiplier"`
+	QuietThreshold  float64           `toml:"quiet_threshold"`
+	Messages        map[string]string `toml:"messages"`
+	Rules           []RateLimitRule   `toml:"rule"`
 }
 
 type KindFilterConfig struct {
+	Enabled      bool  `toml:"enabled"`
+	DryRun       bool  `toml:"dry_run"`
 	AllowedKinds []int `toml:"allowed_kinds"`
 	DeniedKinds  []int `toml:"denied_kinds"`
 }
@@ -72,78 +90,656 @@ type FreshnessRule struct {
 }
 
 type FreshnessFilterConfig struct {
-	DefaultMaxPast   time.Duration   `toml:"default_max_past"`
-	DefaultMaxFuture time.Duration   `toml:"default_max_future"`
-	Rules            []FreshnessRule `toml:"rule"`
+	Enabled            bool            `toml:"enabled"`
+	DryRun             bool            `toml:"dry_run"`
+	DefaultMaxPast     time.Duration   `toml:"default_max_past"`
+	DefaultMaxFuture   time.Duration   `toml:"default_max_future"`
+	StrictFuture       bool            `toml:"strict_future"`
+	ClockSkewTolerance time.Duration   `toml:"clock_skew_tolerance"`
+	UseReceivedTime    bool            `toml:"use_received_time"`
+	Rules              []FreshnessRule `toml:"rule"`
 }
 
 type SizeRule struct {
-	Description string `toml:"description"`
-	Kinds       []int  `toml:"kinds"`
-	MaxSize     int    `toml:"max_size_bytes"`
+	Description     string `toml:"description"`
+	Kinds           []int  `toml:"kinds"`
+	MaxSize         int    `toml:"max_size_bytes"`
+	MaxContentRunes int    `toml:"max_content_runes"`
 }
 
 type SizeFilterConfig struct {
-	DefaultMaxSize int        `toml:"default_max_size_bytes"`
-	Rules          []SizeRule `toml:"rule"`
+	Enabled                 bool              `toml:"enabled"`
+	DryRun                  bool              `toml:"dry_run"`
+	DefaultMaxSize          int               `toml:"default_max_size_bytes"`
+	ExcludeEnvelopeOverhead bool              `toml:"exclude_envelope_overhead"`
+	Messages                map[string]string `toml:"messages"`
+	Rules                   []SizeRule        `toml:"rule"`
+}
+
+type ConditionalReq struct {
+	IfTag       string   `toml:"if_tag"`
+	ThenRequire []string `toml:"then_require"`
 }
 
 type TagRule struct {
-	Kinds        []int          `toml:"kinds"`
-	MaxTags      *int           `toml:"max_tags"`
-	RequiredTags []string       `toml:"required_tags"`
-	MaxTagCounts map[string]int `toml:"max_tag_counts"`
-	Description  string         `toml:"description"`
+	Kinds                   []int               `toml:"kinds"`
+	MaxTags                 *int                `toml:"max_tags"`
+	RequiredTags            []string            `toml:"required_tags"`
+	MaxTagCounts            map[string]int      `toml:"max_tag_counts"`
+	MaxTotalTagBytes        int                 `toml:"max_total_tag_bytes"`
+	MaxDistinctTagNames     int                 `toml:"max_distinct_tag_names"`
+	ConditionalRequirements []ConditionalReq    `toml:"conditional_requirements"`
+	AllowedTagValues        map[string][]string `toml:"allowed_tag_values"`
+	UniqueValueTags         []string            `toml:"unique_value_tags"`
+	Description             string              `toml:"description"`
 }
 
 type TagsFilterConfig struct {
-	Rules []TagRule `toml:"rule"`
+	Enabled           bool      `toml:"enabled"`
+	DryRun            bool      `toml:"dry_run"`
+	NormalizeTagNames bool      `toml:"normalize_tag_names"`
+	Rules             []TagRule `toml:"rule"`
+}
+
+type KeywordSeverity string
+
+const (
+	KeywordSeverityBlock  KeywordSeverity = "block"
+	KeywordSeverityFlag   KeywordSeverity = "flag"
+	KeywordSeverityShadow KeywordSeverity = "shadow"
+)
+
+func (s *KeywordSeverity) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch KeywordSeverity(v) {
+	case KeywordSeverityBlock, KeywordSeverityFlag, KeywordSeverityShadow, "":
+		*s = KeywordSeverity(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid keyword severity: %q (must be block, flag, shadow)", v)
+	}
+}
+
+// KeywordMode selects whether a rule's patterns are forbidden ("deny", the
+// default) or whether content must match at least one of them ("require").
+type KeywordMode string
+
+const (
+	KeywordModeDeny    KeywordMode = "deny"
+	KeywordModeRequire KeywordMode = "require"
+)
+
+func (m *KeywordMode) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch KeywordMode(v) {
+	case KeywordModeDeny, KeywordModeRequire, "":
+		*m = KeywordMode(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid keyword mode: %q (must be deny, require)", v)
+	}
 }
 
 type KeywordRule struct {
-	Description string   `toml:"description"`
-	Kinds       []int    `toml:"kinds"`
-	Words       []string `toml:"words"`
-	Regexps     []string `toml:"regexps"`
+	Description      string          `toml:"description"`
+	Kinds            []int           `toml:"kinds"`
+	Words            []string        `toml:"words"`
+	Regexps          []string        `toml:"regexps"`
+	Mode             KeywordMode     `toml:"mode"`
+	Severity         KeywordSeverity `toml:"severity"`
+	MaxEditDistance  int             `toml:"max_edit_distance"`
+	FoldDiacritics   bool            `toml:"fold_diacritics"`
+	IgnoreCodeBlocks bool            `toml:"ignore_code_blocks"`
+	IgnoreQuotes     bool            `toml:"ignore_quotes"`
+}
+
+type TimeoutAction string
+
+const (
+	TimeoutAccept TimeoutAction = "accept"
+	TimeoutReject TimeoutAction = "reject"
+)
+
+func (a *TimeoutAction) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch TimeoutAction(v) {
+	case TimeoutAccept, TimeoutReject, "":
+		*a = TimeoutAction(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid on_timeout: %q (must be accept, reject)", v)
+	}
 }
 
 type KeywordFilterConfig struct {
-	Enabled bool          `toml:"enabled"`
-	Rules   []KeywordRule `toml:"rule"`
+	Enabled      bool              `toml:"enabled"`
+	DryRun       bool              `toml:"dry_run"`
+	MaxScanBytes int               `toml:"max_scan_bytes"`
+	OnTimeout    TimeoutAction     `toml:"on_timeout"`
+	Messages     map[string]string `toml:"messages"`
+	Rules        []KeywordRule     `toml:"rule"`
 }
 
 type EphemeralChatFilterConfig struct {
-	Enabled                bool          `toml:"enabled"`
-	Kinds                  []int         `toml:"kinds"`
-	MinDelay               time.Duration `toml:"min_delay_between_messages"`
-	MaxCapsRatio           float64       `toml:"max_caps_ratio"`
-	MinLettersForCapsCheck int           `toml:"min_letters_for_caps_check"`
-	MaxRepeatChars         int           `toml:"max_character_repetitions"`
-	MaxWordLength          int           `toml:"max_word_length"`
-	BlockZalgo             bool          `toml:"block_zalgo_text"`
-	CacheSize              int           `toml:"cache_size"`
-	RateLimitRate          float64       `toml:"rate_limit_rate"`
-	RateLimitBurst         int           `toml:"rate_limit_burst"`
-	RequiredPoWOnLimit     int           `toml:"required_pow_on_limit"`
+	Enabled                 bool          `toml:"enabled"`
+	DryRun                  bool          `toml:"dry_run"`
+	Kinds                   []int         `toml:"kinds"`
+	MinDelay                time.Duration `toml:"min_delay_between_messages"`
+	MinDelayBurst           int           `toml:"min_delay_burst"`
+	MaxCapsRatio            float64       `toml:"max_caps_ratio"`
+	MinLettersForCapsCheck  int           `toml:"min_letters_for_caps_check"`
+	CapsIgnoreLinks         bool          `toml:"caps_ignore_links"`
+	MaxRepeatChars          int           `toml:"max_character_repetitions"`
+	MaxWordLength           int           `toml:"max_word_length"`
+	MinWordsForCheck        int           `toml:"min_words_for_check"`
+	BlockZalgo              bool          `toml:"block_zalgo_text"`
+	MaxConsecutiveIdentical int           `toml:"max_consecutive_identical"`
+	CacheSize               int           `toml:"cache_size"`
+	RateLimitRate           float64       `toml:"rate_limit_rate"`
+	RateLimitBurst          int           `toml:"rate_limit_burst"`
+	RequiredPoWOnLimit      int           `toml:"required_pow_on_limit"`
 }
 
-type LanguageFilterConfig struct {
-	Enabled                bool                          `toml:"enabled"`
-	AllowedLanguages       []string                      `toml:"allowed_languages"`
-	KindsToCheck           []int                         `toml:"kinds_to_check"`
-	MinLengthForCheck      int                           `toml:"min_length_for_check"`
-	ApprovedCacheTTL       time.Duration                 `toml:"approved_cache_ttl"`
-	ApprovedCacheSize      int                           `toml:"approved_cache_size"`
+type UndetectedAction string
+
+const (
+	UndetectedReject            UndetectedAction = "reject"
+	UndetectedAccept            UndetectedAction = "accept"
+	UndetectedAcceptBelowLength UndetectedAction = "accept_below_length"
+)
+
+func (a *UndetectedAction) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch UndetectedAction(v) {
+	case UndetectedReject, UndetectedAccept, UndetectedAcceptBelowLength, "":
+		*a = UndetectedAction(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid language_filter.on_undetected: %q (must be reject, accept, accept_below_length)", v)
+	}
+}
+
+type LanguageKindThreshold struct {
+	Kinds                  []int                         `toml:"kinds"`
 	PrimaryAcceptThreshold map[string]map[string]float64 `toml:"primary_accept_threshold"`
 }
 
+type LanguageFilterConfig struct {
+	Enabled                 bool                          `toml:"enabled"`
+	DryRun                  bool                          `toml:"dry_run"`
+	AllowedLanguages        []string                      `toml:"allowed_languages"`
+	KindsToCheck            []int                         `toml:"kinds_to_check"`
+	MinLengthForCheck       int                           `toml:"min_length_for_check"`
+	MinWordsForCheck        int                           `toml:"min_words_for_check"`
+	ApprovedCacheTTL        time.Duration                 `toml:"approved_cache_ttl"`
+	ApprovedCacheSize       int                           `toml:"approved_cache_size"`
+	CachePerKind            bool                          `toml:"cache_per_kind"`
+	PrimaryAcceptThreshold  map[string]map[string]float64 `toml:"primary_accept_threshold"`
+	KindThresholds          []LanguageKindThreshold       `toml:"kind_threshold"`
+	MinConfidenceForAllowed float64                       `toml:"min_confidence_for_allowed"`
+	OnUndetected            UndetectedAction              `toml:"on_undetected"`
+	UndetectedMaxLength     int                           `toml:"undetected_max_length"`
+	SampleRate              float64                       `toml:"sample_rate"`
+	SampleSalt              string                        `toml:"sample_salt"`
+	TrustLanguageTags       bool                          `toml:"trust_language_tags"`
+	AllowEmptyAsPassthrough bool                          `toml:"allow_empty_as_passthrough"`
+}
+
 type RepostAbuseFilterConfig struct {
-	Enabled               bool          `toml:"enabled"`
-	MaxRatio              float64       `toml:"max_ratio"`
-	MinEvents             int           `toml:"min_events"`
-	ResetDuration         time.Duration `toml:"reset_duration"`
-	CacheSize             int           `toml:"cache_size"`
-	CacheTTL              time.Duration `toml:"cache_ttl"`
-	CountRejectAsActivity bool          `toml:"count_reject_as_activity"`
-	RequireNIP21InQuote   bool          `toml:"require_nip21_in_quote"`
+	Enabled                   bool          `toml:"enabled"`
+	DryRun                    bool          `toml:"dry_run"`
+	MaxRatio                  float64       `toml:"max_ratio"`
+	MinEvents                 int           `toml:"min_events"`
+	ResetDuration             time.Duration `toml:"reset_duration"`
+	CacheSize                 int           `toml:"cache_size"`
+	CacheTTL                  time.Duration `toml:"cache_ttl"`
+	CountRejectAsActivity     bool          `toml:"count_reject_as_activity"`
+	RequireNIP21InQuote       bool          `toml:"require_nip21_in_quote"`
+	StatePath                 string        `toml:"state_path"`
+	ForgiveOnOriginal         bool          `toml:"forgive_on_original"`
+	ForgiveAmount             int           `toml:"forgive_amount"`
+	MaxRepeatRepostsPerTarget int           `toml:"max_repeat_reposts_per_target"`
+	MaxConsecutiveReposts     int           `toml:"max_consecutive_reposts"`
+	RepeatRepostWindow        time.Duration `toml:"repeat_repost_window"`
+}
+
+type WoTFilterConfig struct {
+	Enabled bool     `toml:"enabled"`
+	DryRun  bool     `toml:"dry_run"`
+	Anchors []string `toml:"anchors"`
+	MaxHops int      `toml:"max_hops"`
+}
+
+type MuteFilterConfig struct {
+	Enabled       bool     `toml:"enabled"`
+	DryRun        bool     `toml:"dry_run"`
+	Pubkeys       []string `toml:"pubkeys"`
+	BlockMentions bool     `toml:"block_mentions"`
+}
+
+type NIP05FilterConfig struct {
+	Enabled         bool          `toml:"enabled"`
+	DryRun          bool          `toml:"dry_run"`
+	RequireForKinds []int         `toml:"require_for_kinds"`
+	CacheSize       int           `toml:"cache_size"`
+	CacheTTL        time.Duration `toml:"cache_ttl"`
+	FailureTTL      time.Duration `toml:"failure_ttl"`
+	HTTPTimeout     time.Duration `toml:"http_timeout"`
+	SampleRate      float64       `toml:"sample_rate"`
+	SampleSalt      string        `toml:"sample_salt"`
+}
+
+type MediaFilterConfig struct {
+	Enabled             bool     `toml:"enabled"`
+	DryRun              bool     `toml:"dry_run"`
+	Kinds               []int    `toml:"kinds"`
+	AllowedMimePrefixes []string `toml:"allowed_mime_prefixes"`
+	RequireMimeType     bool     `toml:"require_mime_type"`
+	AllowedSchemes      []string `toml:"allowed_schemes"`
+}
+
+type AccountAgeFilterConfig struct {
+	Enabled     bool          `toml:"enabled"`
+	DryRun      bool          `toml:"dry_run"`
+	Kinds       []int         `toml:"kinds"`
+	MinAge      time.Duration `toml:"min_age"`
+	RequiredPoW int           `toml:"required_pow"`
+	CacheSize   int           `toml:"cache_size"`
+	CacheTTL    time.Duration `toml:"cache_ttl"`
+}
+
+type NormalizationFilterConfig struct {
+	Enabled           bool  `toml:"enabled"`
+	DryRun            bool  `toml:"dry_run"`
+	Kinds             []int `toml:"kinds"`
+	MaxInvisibleChars int   `toml:"max_invisible_chars"`
+}
+
+type GeoFilterConfig struct {
+	Enabled          bool     `toml:"enabled"`
+	DryRun           bool     `toml:"dry_run"`
+	AllowedCountries []string `toml:"allowed_countries"`
+	DeniedCountries  []string `toml:"denied_countries"`
+	AllowUnknown     bool     `toml:"allow_unknown"`
+}
+
+type ScaledPoWFilterConfig struct {
+	Enabled        bool  `toml:"enabled"`
+	DryRun         bool  `toml:"dry_run"`
+	Kinds          []int `toml:"kinds"`
+	BaseDifficulty int   `toml:"base_difficulty"`
+	BytesPerBit    int   `toml:"bytes_per_bit"`
+}
+
+type QuotaRule struct {
+	Description string `toml:"description"`
+	Kinds       []int  `toml:"kinds"`
+	MaxEvents   int    `toml:"max_events"`
+}
+
+type QuotaFilterConfig struct {
+	Enabled            bool          `toml:"enabled"`
+	DryRun             bool          `toml:"dry_run"`
+	By                 RateLimiterBy `toml:"by"`
+	MaxEventsPerPeriod int           `toml:"max_events_per_period"`
+	Period             time.Duration `toml:"period"`
+	CacheSize          int           `toml:"cache_size"`
+	Rules              []QuotaRule   `toml:"rule"`
+}
+
+type IPReputationFilterConfig struct {
+	Enabled     bool     `toml:"enabled"`
+	DryRun      bool     `toml:"dry_run"`
+	DeniedCIDRs []string `toml:"denied_cidrs"`
+}
+
+type ContentFanoutFilterConfig struct {
+	Enabled            bool          `toml:"enabled"`
+	DryRun             bool          `toml:"dry_run"`
+	Kinds              []int         `toml:"kinds"`
+	MaxDistinctPubkeys int           `toml:"max_distinct_pubkeys"`
+	MinContentLength   int           `toml:"min_content_length"`
+	Window             time.Duration `toml:"window"`
+	CacheSize          int           `toml:"cache_size"`
+}
+
+type RelayHintFilterConfig struct {
+	Enabled      bool     `toml:"enabled"`
+	DryRun       bool     `toml:"dry_run"`
+	Kinds        []int    `toml:"kinds"`
+	RequireHint  bool     `toml:"require_hint"`
+	AllowedHints []string `toml:"allowed_hints"`
+}
+
+type AddressableFilterConfig struct {
+	Enabled    bool   `toml:"enabled"`
+	DryRun     bool   `toml:"dry_run"`
+	MaxDLength int    `toml:"max_d_length"`
+	DPattern   string `toml:"d_pattern"`
+}
+
+type ThreadRateFilterConfig struct {
+	Enabled      bool          `toml:"enabled"`
+	DryRun       bool          `toml:"dry_run"`
+	Kinds        []int         `toml:"kinds"`
+	MaxPerThread int           `toml:"max_per_thread"`
+	Window       time.Duration `toml:"window"`
+	CacheSize    int           `toml:"cache_size"`
+}
+
+type AccountFarmingFilterConfig struct {
+	Enabled         bool          `toml:"enabled"`
+	DryRun          bool          `toml:"dry_run"`
+	MaxPubkeysPerIP int           `toml:"max_pubkeys_per_ip"`
+	Window          time.Duration `toml:"window"`
+	CacheSize       int           `toml:"cache_size"`
+	IPv4Prefix      int           `toml:"ipv4_prefix"`
+	IPv6Prefix      int           `toml:"ipv6_prefix"`
+}
+
+type SchemaFieldType string
+
+const (
+	SchemaFieldString  SchemaFieldType = "string"
+	SchemaFieldNumber  SchemaFieldType = "number"
+	SchemaFieldBoolean SchemaFieldType = "boolean"
+	SchemaFieldObject  SchemaFieldType = "object"
+	SchemaFieldArray   SchemaFieldType = "array"
+	SchemaFieldAny     SchemaFieldType = "any"
+)
+
+func (t *SchemaFieldType) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch SchemaFieldType(v) {
+	case SchemaFieldString, SchemaFieldNumber, SchemaFieldBoolean, SchemaFieldObject, SchemaFieldArray, SchemaFieldAny, "":
+		*t = SchemaFieldType(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid schema field type: %q (must be string, number, boolean, object, array, any)", v)
+	}
+}
+
+// ContentSchema lists the top-level JSON fields that must be present in an
+// event's content, keyed by field name. An empty type accepts any value.
+type ContentSchema struct {
+	RequiredFields map[string]SchemaFieldType `toml:"required_fields"`
+}
+
+type ContentSchemaFilterConfig struct {
+	Enabled bool                  `toml:"enabled"`
+	DryRun  bool                  `toml:"dry_run"`
+	Schemas map[int]ContentSchema `toml:"schemas"`
+}
+
+type ShadowBanFilterConfig struct {
+	Enabled bool     `toml:"enabled"`
+	DryRun  bool     `toml:"dry_run"`
+	Pubkeys []string `toml:"pubkeys"`
+}
+
+type ReferenceIntegrityFilterConfig struct {
+	Enabled  bool     `toml:"enabled"`
+	DryRun   bool     `toml:"dry_run"`
+	TagNames []string `toml:"tag_names"`
+}
+
+type ScoringRunnerConfig struct {
+	DryRun         bool               `toml:"dry_run"`
+	BlockThreshold float64            `toml:"block_threshold"`
+	DefaultWeight  float64            `toml:"default_weight"`
+	FilterWeights  map[string]float64 `toml:"filter_weights"`
+	ReasonWeights  map[string]float64 `toml:"reason_weights"`
+}
+
+type ProtectedEventFilterConfig struct {
+	Enabled bool `toml:"enabled"`
+	DryRun  bool `toml:"dry_run"`
+}
+
+type ScheduleWindow struct {
+	Days      []string `toml:"days"`
+	StartHour int      `toml:"start_hour"`
+	EndHour   int      `toml:"end_hour"`
+}
+
+type ScheduleRule struct {
+	Description string           `toml:"description"`
+	Kinds       []int            `toml:"kinds"`
+	Windows     []ScheduleWindow `toml:"window"`
+}
+
+type ScheduleFilterConfig struct {
+	Enabled        bool           `toml:"enabled"`
+	DryRun         bool           `toml:"dry_run"`
+	Timezone       string         `toml:"timezone"`
+	UseArrivalTime bool           `toml:"use_arrival_time"`
+	Rules          []ScheduleRule `toml:"rule"`
+}
+
+type StructureFilterConfig struct {
+	Enabled       bool  `toml:"enabled"`
+	DryRun        bool  `toml:"dry_run"`
+	Kinds         []int `toml:"kinds"`
+	MaxLines      int   `toml:"max_lines"`
+	MaxLineLength int   `toml:"max_line_length"`
+}
+
+type DataURIFilterConfig struct {
+	Enabled         bool  `toml:"enabled"`
+	DryRun          bool  `toml:"dry_run"`
+	Kinds           []int `toml:"kinds"`
+	MaxDataURIs     int   `toml:"max_data_uris"`
+	MaxDataURIBytes int   `toml:"max_data_uri_bytes"`
+}
+
+type StructuralFilterConfig struct {
+	Enabled bool `toml:"enabled"`
+	DryRun  bool `toml:"dry_run"`
+}
+
+type NIP10FilterConfig struct {
+	Enabled bool `toml:"enabled"`
+	DryRun  bool `toml:"dry_run"`
+	Strict  bool `toml:"strict"`
+}
+
+type SheddingPoint struct {
+	Load              float64 `toml:"load"`
+	RejectProbability float64 `toml:"reject_probability"`
+}
+
+type BackpressureFilterConfig struct {
+	Enabled       bool            `toml:"enabled"`
+	DryRun        bool            `toml:"dry_run"`
+	Kinds         []int           `toml:"kinds"`
+	SheddingCurve []SheddingPoint `toml:"shedding_curve"`
+}
+
+type ProfileUpdateFilterConfig struct {
+	Enabled     bool          `toml:"enabled"`
+	DryRun      bool          `toml:"dry_run"`
+	MinInterval time.Duration `toml:"min_interval"`
+	CacheSize   int           `toml:"cache_size"`
+	CacheTTL    time.Duration `toml:"cache_ttl"`
+}
+
+type RuneRange struct {
+	Lo rune `toml:"lo"`
+	Hi rune `toml:"hi"`
+}
+
+type CharsetRule struct {
+	Description   string      `toml:"description"`
+	Kinds         []int       `toml:"kinds"`
+	AllowedRanges []RuneRange `toml:"allowed_ranges"`
+}
+
+type CharsetFilterConfig struct {
+	Enabled bool          `toml:"enabled"`
+	DryRun  bool          `toml:"dry_run"`
+	Rules   []CharsetRule `toml:"rule"`
+}
+
+type FeatureFilterConfig struct {
+	Enabled bool  `toml:"enabled"`
+	DryRun  bool  `toml:"dry_run"`
+	Kinds   []int `toml:"kinds"`
+}
+
+type PileOnFilterConfig struct {
+	Enabled            bool          `toml:"enabled"`
+	DryRun             bool          `toml:"dry_run"`
+	Kinds              []int         `toml:"kinds"`
+	MaxEventsPerTarget int           `toml:"max_events_per_target"`
+	Window             time.Duration `toml:"window"`
+	CacheSize          int           `toml:"cache_size"`
+}
+
+type ClientFilterConfig struct {
+	Enabled        bool     `toml:"enabled"`
+	DryRun         bool     `toml:"dry_run"`
+	AllowedClients []string `toml:"allowed_clients"`
+	DeniedClients  []string `toml:"denied_clients"`
+	AllowUntagged  bool     `toml:"allow_untagged"`
+}
+
+type KarmaFilterConfig struct {
+	Enabled       bool          `toml:"enabled"`
+	DryRun        bool          `toml:"dry_run"`
+	Kinds         []int         `toml:"kinds"`
+	MinKarma      int           `toml:"min_karma"`
+	RejectUnknown bool          `toml:"reject_unknown"`
+	CacheSize     int           `toml:"cache_size"`
+	CacheTTL      time.Duration `toml:"cache_ttl"`
+}
+
+type ZapRequestFilterConfig struct {
+	Enabled         bool  `toml:"enabled"`
+	DryRun          bool  `toml:"dry_run"`
+	MinAmount       int64 `toml:"min_amount_msat"`
+	MaxAmount       int64 `toml:"max_amount_msat"`
+	RequireEventTag bool  `toml:"require_event_tag"`
+}
+
+type MaintenanceFilterConfig struct {
+	Enabled       bool     `toml:"enabled"`
+	DryRun        bool     `toml:"dry_run"`
+	ReadOnly      bool     `toml:"read_only"`
+	Reason        string   `toml:"reason"`
+	ExemptPubkeys []string `toml:"exempt_pubkeys"`
+}
+
+type ContactListFilterConfig struct {
+	Enabled            bool    `toml:"enabled"`
+	DryRun             bool    `toml:"dry_run"`
+	MaxContacts        int     `toml:"max_contacts"`
+	RejectDuplicates   bool    `toml:"reject_duplicates"`
+	MaxNonContactRatio float64 `toml:"max_non_contact_ratio"`
+}
+
+type ConditionalPoWFilterConfig struct {
+	Enabled            bool     `toml:"enabled"`
+	DryRun             bool     `toml:"dry_run"`
+	Kinds              []int    `toml:"kinds"`
+	MaxLinks           int      `toml:"max_links"`
+	MaxCapsRatio       float64  `toml:"max_caps_ratio"`
+	MinLettersForCaps  int      `toml:"min_letters_for_caps"`
+	Keywords           []string `toml:"keywords"`
+	SuspicionThreshold int      `toml:"suspicion_threshold"`
+	RequiredDifficulty int      `toml:"required_difficulty"`
+}
+
+type HashtagFilterConfig struct {
+	Enabled          bool  `toml:"enabled"`
+	DryRun           bool  `toml:"dry_run"`
+	Kinds            []int `toml:"kinds"`
+	MaxHashtags      int   `toml:"max_hashtags"`
+	MaxHashtagLength int   `toml:"max_hashtag_length"`
+}
+
+type DeletionFilterConfig struct {
+	Enabled          bool `toml:"enabled"`
+	DryRun           bool `toml:"dry_run"`
+	RequireOwnership bool `toml:"require_ownership"`
+}
+
+type EmptyContentFilterConfig struct {
+	Enabled             bool  `toml:"enabled"`
+	DryRun              bool  `toml:"dry_run"`
+	RequireContentKinds []int `toml:"require_content_kinds"`
+	RequireEmptyKinds   []int `toml:"require_empty_kinds"`
+}
+
+type ConnectionQuotaFilterConfig struct {
+	Enabled                bool          `toml:"enabled"`
+	DryRun                 bool          `toml:"dry_run"`
+	MaxEventsPerConnection int           `toml:"max_events_per_connection"`
+	CacheSize              int           `toml:"cache_size"`
+	TTL                    time.Duration `toml:"ttl"`
+}
+
+type TrustGateConfig struct {
+	Enabled        bool     `toml:"enabled"`
+	DryRun         bool     `toml:"dry_run"`
+	TrustedPubkeys []string `toml:"trusted_pubkeys"`
+}
+
+type ReplyDepthMode string
+
+const (
+	ReplyDepthHeuristic ReplyDepthMode = "heuristic"
+	ReplyDepthLookup    ReplyDepthMode = "lookup"
+)
+
+func (m *ReplyDepthMode) UnmarshalText(text []byte) error {
+	v := string(text)
+	switch ReplyDepthMode(v) {
+	case ReplyDepthHeuristic, ReplyDepthLookup, "":
+		*m = ReplyDepthMode(v)
+		return nil
+	default:
+		return fmt.Errorf("invalid reply depth mode: %q (must be heuristic, lookup)", v)
+	}
+}
+
+type ReplyDepthFilterConfig struct {
+	Enabled  bool           `toml:"enabled"`
+	DryRun   bool           `toml:"dry_run"`
+	Kinds    []int          `toml:"kinds"`
+	Mode     ReplyDepthMode `toml:"mode"`
+	MaxDepth int            `toml:"max_depth"`
+}
+
+type RelayListFilterConfig struct {
+	Enabled         bool `toml:"enabled"`
+	DryRun          bool `toml:"dry_run"`
+	MaxRelays       int  `toml:"max_relays"`
+	ValidateMarkers bool `toml:"validate_markers"`
+}
+
+type WebhookFilterConfig struct {
+	Enabled     bool          `toml:"enabled"`
+	DryRun      bool          `toml:"dry_run"`
+	URL         string        `toml:"url"`
+	Kinds       []int         `toml:"kinds"`
+	HTTPTimeout time.Duration `toml:"http_timeout"`
+	MaxRetries  int           `toml:"max_retries"`
+	CacheSize   int           `toml:"cache_size"`
+	CacheTTL    time.Duration `toml:"cache_ttl"`
+	FailOpen    bool          `toml:"fail_open"`
+}
+
+type CopypastaFilterConfig struct {
+	Enabled              bool          `toml:"enabled"`
+	DryRun               bool          `toml:"dry_run"`
+	Kinds                []int         `toml:"kinds"`
+	MaxGlobalOccurrences int           `toml:"max_global_occurrences"`
+	MinContentLength     int           `toml:"min_content_length"`
+	Window               time.Duration `toml:"window"`
+	CacheSize            int           `toml:"cache_size"`
+}
+
+type QuoteHintFilterConfig struct {
+	Enabled bool `toml:"enabled"`
+	DryRun  bool `toml:"dry_run"`
 }
@@ -0,0 +1,38 @@
+// nip/21_refs.go
+package nip
+
+import (
+	"regexp"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// nostrRefRe matches bech32 entities with or without the NIP-21 "nostr:"
+// scheme. The data part is restricted to the bech32 charset.
+var nostrRefRe = regexp.MustCompile(`\b(npub|nprofile|note|nevent|naddr)1[02-9ac-hj-np-z]+\b`)
+
+// NostrRef is a bech32 nostr reference found in content.
+type NostrRef struct {
+	// Type is the bech32 prefix: npub, nprofile, note, nevent or naddr.
+	Type string
+	// Raw is the bech32 string as it appears in content, without "nostr:".
+	Raw string
+	// Value is the decoded entity: a hex string for npub and note,
+	// nostr.ProfilePointer for nprofile, nostr.EventPointer for nevent and
+	// nostr.EntityPointer for naddr.
+	Value any
+}
+
+// ParseNostrRefs returns the decodable bech32 references in content in order
+// of appearance. Matches that fail to decode are skipped.
+func ParseNostrRefs(content string) []NostrRef {
+	var refs []NostrRef
+	for _, raw := range nostrRefRe.FindAllString(content, -1) {
+		prefix, value, err := nip19.Decode(raw)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, NostrRef{Type: prefix, Raw: raw, Value: value})
+	}
+	return refs
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+	"github.com/lessucettes/adresu-kit/nip"
+)
+
+const (
+	accountAgeFilterName = "AccountAgeFilter"
+)
+
+type AccountAgeFilter struct {
+	filterBase
+
+	mu        sync.Mutex
+	cfg       *config.AccountAgeFilterConfig
+	kinds     map[int]struct{}
+	firstSeen *lru.LRU[string, time.Time]
+}
+
+func NewAccountAgeFilter(cfg *config.AccountAgeFilterConfig) (*AccountAgeFilter, error) {
+	if !cfg.Enabled {
+		return &AccountAgeFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 100000
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	if ttl < cfg.MinAge {
+		slog.Warn("AccountAgeFilter config warning: cache_ttl is shorter than min_age; pubkeys will never mature", "cache_ttl", ttl, "min_age", cfg.MinAge)
+	}
+
+	filter := &AccountAgeFilter{
+		cfg:       cfg,
+		kinds:     kinds,
+		firstSeen: lru.NewLRU[string, time.Time](size, nil, ttl),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *AccountAgeFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(accountAgeFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	// First contact is recorded for every kind so that age accrues even
+	// while the pubkey only publishes unchecked kinds.
+	now := f.now()
+	f.mu.Lock()
+	first, ok := f.firstSeen.Get(event.PubKey)
+	if !ok {
+		first = now
+		f.firstSeen.Add(event.PubKey, first)
+	}
+	f.mu.Unlock()
+
+	if _, checked := f.kinds[event.Kind]; !checked {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	age := now.Sub(first)
+	if age >= f.cfg.MinAge {
+		return newResult(true, "account_age_ok", nil)
+	}
+
+	if f.cfg.RequiredPoW > 0 && nip.IsPoWValid(event, f.cfg.RequiredPoW) {
+		return newResult(true, "account_too_new_bypassed_by_pow", nil)
+	}
+
+	reason := fmt.Sprintf("account_too_new:age_%s,min_%s,required_pow_%d", age.Round(time.Second), f.cfg.MinAge, f.cfg.RequiredPoW)
+	return newResult(false, reason, nil)
+}
+
+// Close releases the filter's caches.
+func (f *AccountAgeFilter) Close() error {
+	purgeCache(f.firstSeen)
+	return nil
+}
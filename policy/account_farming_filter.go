@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	accountFarmingFilterName = "AccountFarmingFilter"
+)
+
+type AccountFarmingFilter struct {
+	filterBase
+
+	mu      sync.Mutex
+	cfg     *config.AccountFarmingFilterConfig
+	pubkeys *lru.LRU[string, map[string]struct{}]
+}
+
+func NewAccountFarmingFilter(cfg *config.AccountFarmingFilterConfig) (*AccountFarmingFilter, error) {
+	if !cfg.Enabled {
+		return &AccountFarmingFilter{cfg: cfg}, nil
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	filter := &AccountFarmingFilter{
+		cfg:     cfg,
+		pubkeys: lru.NewLRU[string, map[string]struct{}](size, nil, window),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *AccountFarmingFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(accountFarmingFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxPubkeysPerIP <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	remoteIP, ok := meta["remote_ip"].(string)
+	if !ok || remoteIP == "" {
+		return newResult(true, "remote_ip_unknown", nil)
+	}
+	key := normalizeIPWithOptionalPrefixes(remoteIP, f.cfg.IPv4Prefix, f.cfg.IPv6Prefix)
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(event, key)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked admits event.PubKey for the IP key and records it. The caller must hold f.mu.
+func (f *AccountFarmingFilter) evaluateLocked(event *nostr.Event, key string) (bool, string) {
+	seen, ok := f.pubkeys.Get(key)
+	if !ok {
+		// The entry is added once so the window starts at the first pubkey.
+		seen = make(map[string]struct{})
+		f.pubkeys.Add(key, seen)
+	}
+
+	if _, known := seen[event.PubKey]; known {
+		return true, "pubkey_known_for_ip"
+	}
+	if len(seen) >= f.cfg.MaxPubkeysPerIP {
+		reason := fmt.Sprintf("too_many_pubkeys_from_ip:max_%d", f.cfg.MaxPubkeysPerIP)
+		return false, reason
+	}
+	seen[event.PubKey] = struct{}{}
+
+	return true, "pubkey_accepted_for_ip"
+}
+
+// Close releases the filter's caches.
+func (f *AccountFarmingFilter) Close() error {
+	purgeCache(f.pubkeys)
+	return nil
+}
@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	addressableFilterName = "AddressableFilter"
+)
+
+type AddressableFilter struct {
+	filterBase
+
+	cfg      *config.AddressableFilterConfig
+	dPattern *regexp.Regexp
+}
+
+func NewAddressableFilter(cfg *config.AddressableFilterConfig) (*AddressableFilter, error) {
+	var dPattern *regexp.Regexp
+	if cfg.DPattern != "" {
+		var err error
+		dPattern, err = regexp.Compile(cfg.DPattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile d_pattern '%s': %w", cfg.DPattern, err)
+		}
+	}
+
+	filter := &AddressableFilter{
+		cfg:      cfg,
+		dPattern: dPattern,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *AddressableFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(addressableFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if !nostr.IsAddressableKind(event.Kind) {
+		return newResult(true, "kind_not_addressable", nil)
+	}
+
+	dTag := event.Tags.Find("d")
+	if len(dTag) < 2 || dTag[1] == "" {
+		return newResult(false, "missing_valid_d_tag", nil)
+	}
+	d := dTag[1]
+
+	if f.cfg.MaxDLength > 0 && len(d) > f.cfg.MaxDLength {
+		reason := fmt.Sprintf("invalid_d_tag:length_%d,max_%d", len(d), f.cfg.MaxDLength)
+		return newResult(false, reason, nil)
+	}
+	if f.dPattern != nil && !f.dPattern.MatchString(d) {
+		return newResult(false, "invalid_d_tag:pattern_mismatch", nil)
+	}
+
+	return newResult(true, "d_tag_ok", nil)
+}
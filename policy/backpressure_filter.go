@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	backpressureFilterName = "BackpressureFilter"
+)
+
+// defaultSheddingCurve starts shedding at 80% load and rejects everything at
+// full load.
+var defaultSheddingCurve = []config.SheddingPoint{
+	{Load: 0.8, RejectProbability: 0},
+	{Load: 1.0, RejectProbability: 1},
+}
+
+// LoadFunc reports the current relay load between 0 (idle) and 1 (saturated).
+type LoadFunc func() float64
+
+// BackpressureFilter sheds configured kinds at random with a probability that
+// follows the shedding curve as load rises. It protects the relay as a whole
+// and is not meant to be fair to individual users.
+type BackpressureFilter struct {
+	filterBase
+
+	cfg   *config.BackpressureFilterConfig
+	load  LoadFunc
+	kinds map[int]struct{}
+	curve []config.SheddingPoint
+}
+
+func NewBackpressureFilter(cfg *config.BackpressureFilterConfig, load LoadFunc) (*BackpressureFilter, error) {
+	if !cfg.Enabled {
+		return &BackpressureFilter{cfg: cfg}, nil
+	}
+	if load == nil {
+		return nil, errors.New("backpressure filter enabled but load func is nil")
+	}
+
+	curve := slices.Clone(cfg.SheddingCurve)
+	if len(curve) == 0 {
+		curve = defaultSheddingCurve
+	}
+	slices.SortFunc(curve, func(a, b config.SheddingPoint) int {
+		return cmp.Compare(a.Load, b.Load)
+	})
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &BackpressureFilter{
+		cfg:   cfg,
+		load:  load,
+		kinds: kinds,
+		curve: curve,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *BackpressureFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(backpressureFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	load := f.load()
+	if p := f.rejectProbability(load); p > 0 && rand.Float64() < p {
+		return newResult(false, fmt.Sprintf("load_shedding:load_%.2f", load), nil)
+	}
+
+	return newResult(true, "load_ok", nil)
+}
+
+// rejectProbability interpolates the curve linearly at load. Loads outside the
+// curve take the value of the nearest point.
+func (f *BackpressureFilter) rejectProbability(load float64) float64 {
+	curve := f.curve
+	if load <= curve[0].Load {
+		return curve[0].RejectProbability
+	}
+	for i := 1; i < len(curve); i++ {
+		lo, hi := curve[i-1], curve[i]
+		if load <= hi.Load {
+			if hi.Load == lo.Load {
+				return hi.RejectProbability
+			}
+			t := (load - lo.Load) / (hi.Load - lo.Load)
+			return lo.RejectProbability + t*(hi.RejectProbability-lo.RejectProbability)
+		}
+	}
+	return curve[len(curve)-1].RejectProbability
+}
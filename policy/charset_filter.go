@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	charsetFilterName = "CharsetFilter"
+)
+
+// CharsetFilter rejects content containing runes outside the allowed ranges
+// configured for the event's kind. Whitespace and punctuation are always
+// allowed.
+type CharsetFilter struct {
+	filterBase
+
+	cfg    *config.CharsetFilterConfig
+	ranges map[int][]config.RuneRange
+}
+
+func NewCharsetFilter(cfg *config.CharsetFilterConfig) (*CharsetFilter, error) {
+	warnDisabledWithRules(charsetFilterName, cfg.Enabled, len(cfg.Rules))
+	if !cfg.Enabled {
+		return &CharsetFilter{cfg: cfg}, nil
+	}
+
+	ranges := make(map[int][]config.RuneRange)
+	for _, rule := range cfg.Rules {
+		for _, r := range rule.AllowedRanges {
+			if r.Lo > r.Hi {
+				return nil, fmt.Errorf("invalid rune range U+%04X-U+%04X in charset rule '%s'", r.Lo, r.Hi, rule.Description)
+			}
+		}
+		for _, kind := range rule.Kinds {
+			ranges[kind] = append(ranges[kind], rule.AllowedRanges...)
+		}
+	}
+
+	filter := &CharsetFilter{
+		cfg:    cfg,
+		ranges: ranges,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *CharsetFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(charsetFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	ranges, ok := f.ranges[event.Kind]
+	if !ok {
+		return newResult(true, "no_rules_for_kind", nil)
+	}
+
+	for _, r := range event.Content {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || runeInRanges(r, ranges) {
+			continue
+		}
+		return newResult(false, fmt.Sprintf("disallowed_character:U+%04X", r), nil)
+	}
+
+	return newResult(true, "charset_ok", nil)
+}
+
+func runeInRanges(r rune, ranges []config.RuneRange) bool {
+	for _, rr := range ranges {
+		if r >= rr.Lo && r <= rr.Hi {
+			return true
+		}
+	}
+	return false
+}
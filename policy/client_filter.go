@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	clientFilterName = "ClientFilter"
+)
+
+// ClientFilter allows or denies events by their NIP-89 `client` tag. List
+// entries match either the client name (case-insensitively) or its `31990:`
+// address coordinate. The deny list wins over the allow list.
+type ClientFilter struct {
+	filterBase
+
+	cfg     *config.ClientFilterConfig
+	allowed map[string]struct{}
+	denied  map[string]struct{}
+}
+
+func NewClientFilter(cfg *config.ClientFilterConfig) (*ClientFilter, error) {
+	warnDisabledWithRules(clientFilterName, cfg.Enabled, len(cfg.AllowedClients)+len(cfg.DeniedClients))
+
+	filter := &ClientFilter{
+		cfg:     cfg,
+		allowed: clientSet(cfg.AllowedClients),
+		denied:  clientSet(cfg.DeniedClients),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ClientFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(clientFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	tag := event.Tags.Find("client")
+	if tag == nil {
+		if f.cfg.AllowUntagged {
+			return newResult(true, "client_tag_absent", nil)
+		}
+		return newResult(false, "missing_client_tag", nil)
+	}
+
+	name := tag[1]
+	keys := []string{strings.ToLower(name)}
+	if len(tag) > 2 && tag[2] != "" {
+		keys = append(keys, tag[2])
+	}
+
+	for _, key := range keys {
+		if _, ok := f.denied[key]; ok {
+			return newResult(false, fmt.Sprintf("client_not_permitted:'%s'", name), nil)
+		}
+	}
+	if len(f.allowed) > 0 {
+		for _, key := range keys {
+			if _, ok := f.allowed[key]; ok {
+				return newResult(true, "client_allowed", nil)
+			}
+		}
+		return newResult(false, fmt.Sprintf("client_not_permitted:'%s'", name), nil)
+	}
+
+	return newResult(true, "client_allowed", nil)
+}
+
+// clientSet lowercases names but keeps address coordinates as they are.
+func clientSet(clients []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(clients))
+	for _, c := range clients {
+		if !strings.Contains(c, ":") {
+			c = strings.ToLower(c)
+		}
+		set[c] = struct{}{}
+	}
+	return set
+}
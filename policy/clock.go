@@ -0,0 +1,16 @@
+package policy
+
+import "time"
+
+// Clock supplies the current time to filters that depend on it, so that
+// callers can substitute a fixed or simulated clock.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the Clock backed by time.Now.
+var SystemClock Clock = systemClock{}
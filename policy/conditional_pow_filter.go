@@ -0,0 +1,115 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+	"github.com/lessucettes/adresu-kit/nip"
+)
+
+const (
+	conditionalPoWFilterName = "ConditionalPoWFilter"
+)
+
+// ConditionalPoWFilter requires NIP-13 proof of work only from events whose
+// content looks suspicious. Each heuristic that fires (too many links, too
+// many capitals, a listed keyword) adds one to the event's suspicion, and
+// events reaching SuspicionThreshold must carry RequiredDifficulty.
+type ConditionalPoWFilter struct {
+	filterBase
+
+	cfg      *config.ConditionalPoWFilterConfig
+	kinds    map[int]struct{}
+	keywords *regexp.Regexp
+}
+
+func NewConditionalPoWFilter(cfg *config.ConditionalPoWFilterConfig) (*ConditionalPoWFilter, error) {
+	if !cfg.Enabled {
+		return &ConditionalPoWFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	var keywords *regexp.Regexp
+	if len(cfg.Keywords) > 0 {
+		quoted := make([]string, len(cfg.Keywords))
+		for i, word := range cfg.Keywords {
+			quoted[i] = regexp.QuoteMeta(word)
+		}
+		var err error
+		keywords, err = regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+		if err != nil {
+			return nil, fmt.Errorf("internal error compiling keywords: %w", err)
+		}
+	}
+
+	filter := &ConditionalPoWFilter{
+		cfg:      cfg,
+		kinds:    kinds,
+		keywords: keywords,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ConditionalPoWFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(conditionalPoWFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	suspicion := f.suspicion(event.Content)
+	if meta != nil {
+		meta["suspicion"] = suspicion
+	}
+
+	threshold := max(f.cfg.SuspicionThreshold, 1)
+	if suspicion < threshold {
+		return newResult(true, "content_not_suspicious", nil)
+	}
+	if nip.IsPoWValid(event, f.cfg.RequiredDifficulty) {
+		return newResult(true, "suspicious_content_has_pow", nil)
+	}
+
+	reason := fmt.Sprintf("pow_required_for_suspicious_content:difficulty_%d", f.cfg.RequiredDifficulty)
+	return newResult(false, reason, nil)
+}
+
+// suspicion counts the heuristics content trips.
+func (f *ConditionalPoWFilter) suspicion(content string) int {
+	score := 0
+	if f.cfg.MaxLinks > 0 && len(linkRe.FindAllStringIndex(content, f.cfg.MaxLinks+1)) > f.cfg.MaxLinks {
+		score++
+	}
+	if f.cfg.MaxCapsRatio > 0 {
+		minLetters := f.cfg.MinLettersForCaps
+		if minLetters <= 0 {
+			minLetters = 20
+		}
+		if ratio, letters := capsRatio(content); letters > minLetters && ratio > f.cfg.MaxCapsRatio {
+			score++
+		}
+	}
+	if f.keywords != nil && f.keywords.MatchString(content) {
+		score++
+	}
+	return score
+}
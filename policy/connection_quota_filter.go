@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	connectionQuotaFilterName = "ConnectionQuotaFilter"
+)
+
+// ConnectionQuotaFilter caps the number of events one WebSocket connection,
+// identified by meta["connection_id"], may submit, regardless of pubkey or
+// IP. Counters expire TTL after a connection's first event, or earlier via
+// Forget when the relay sees the connection close.
+type ConnectionQuotaFilter struct {
+	filterBase
+
+	mu       sync.Mutex
+	cfg      *config.ConnectionQuotaFilterConfig
+	counters *lru.LRU[string, *int]
+}
+
+func NewConnectionQuotaFilter(cfg *config.ConnectionQuotaFilterConfig) (*ConnectionQuotaFilter, error) {
+	if !cfg.Enabled {
+		return &ConnectionQuotaFilter{cfg: cfg}, nil
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	filter := &ConnectionQuotaFilter{
+		cfg:      cfg,
+		counters: lru.NewLRU[string, *int](size, nil, ttl),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ConnectionQuotaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(connectionQuotaFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxEventsPerConnection <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	connID, _ := meta["connection_id"].(string)
+	if connID == "" {
+		return newResult(true, "connection_id_missing", nil)
+	}
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(connID)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked checks and charges the connection's counter. The caller must hold f.mu.
+func (f *ConnectionQuotaFilter) evaluateLocked(connID string) (bool, string) {
+	count, ok := f.counters.Get(connID)
+	if !ok {
+		count = new(int)
+		f.counters.Add(connID, count)
+	}
+	if *count >= f.cfg.MaxEventsPerConnection {
+		reason := fmt.Sprintf("quota_connection_exceeded:max_%d", f.cfg.MaxEventsPerConnection)
+		return false, reason
+	}
+	*count++
+
+	return true, "connection_quota_ok"
+}
+
+// Forget drops the counter for a closed connection.
+func (f *ConnectionQuotaFilter) Forget(connectionID string) {
+	if f.counters != nil {
+		f.counters.Remove(connectionID)
+	}
+}
+
+// Close releases the counters.
+func (f *ConnectionQuotaFilter) Close() error {
+	purgeCache(f.counters)
+	return nil
+}
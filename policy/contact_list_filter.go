@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	contactListFilterName = "ContactListFilter"
+)
+
+// ContactListFilter limits kind 3 contact lists: the number of `p` tags,
+// repeated pubkeys, and the share of tags that are not contacts at all.
+type ContactListFilter struct {
+	filterBase
+
+	cfg *config.ContactListFilterConfig
+}
+
+func NewContactListFilter(cfg *config.ContactListFilterConfig) (*ContactListFilter, error) {
+	filter := &ContactListFilter{cfg: cfg}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ContactListFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(contactListFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if event.Kind != nostr.KindFollowList {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	contacts := 0
+	var seen map[string]struct{}
+	if f.cfg.RejectDuplicates {
+		seen = make(map[string]struct{}, len(event.Tags))
+	}
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "p" {
+			continue
+		}
+		contacts++
+		if seen != nil {
+			if _, dup := seen[tag[1]]; dup {
+				return newResult(false, fmt.Sprintf("duplicate_contact:'%s'", tag[1]), nil)
+			}
+			seen[tag[1]] = struct{}{}
+		}
+	}
+
+	if f.cfg.MaxContacts > 0 && contacts > f.cfg.MaxContacts {
+		reason := fmt.Sprintf("contact_list_too_large:got_%d,max_%d", contacts, f.cfg.MaxContacts)
+		return newResult(false, reason, nil)
+	}
+
+	if f.cfg.MaxNonContactRatio > 0 && len(event.Tags) > 0 {
+		ratio := float64(len(event.Tags)-contacts) / float64(len(event.Tags))
+		if ratio > f.cfg.MaxNonContactRatio {
+			reason := fmt.Sprintf("too_many_non_contact_tags:ratio_%.2f,max_%.2f", ratio, f.cfg.MaxNonContactRatio)
+			return newResult(false, reason, nil)
+		}
+	}
+
+	if meta != nil {
+		meta["contact_count"] = contacts
+	}
+	return newResult(true, "contact_list_ok", nil)
+}
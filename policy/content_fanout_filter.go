@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	contentFanoutFilterName = "ContentFanoutFilter"
+)
+
+type ContentFanoutFilter struct {
+	filterBase
+
+	mu      sync.Mutex
+	cfg     *config.ContentFanoutFilterConfig
+	kinds   map[int]struct{}
+	posters *lru.LRU[string, map[string]struct{}]
+}
+
+func NewContentFanoutFilter(cfg *config.ContentFanoutFilterConfig) (*ContentFanoutFilter, error) {
+	if !cfg.Enabled {
+		return &ContentFanoutFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	filter := &ContentFanoutFilter{
+		cfg:     cfg,
+		kinds:   kinds,
+		posters: lru.NewLRU[string, map[string]struct{}](size, nil, window),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ContentFanoutFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(contentFanoutFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxDistinctPubkeys <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	if f.cfg.MinContentLength > 0 {
+		if len(normalizeForHash(event.Content)) < f.cfg.MinContentLength {
+			return newResult(true, "content_too_short", nil)
+		}
+	}
+	if strings.TrimSpace(event.Content) == "" {
+		return newResult(true, "content_too_short", nil)
+	}
+	hash := contentHash(event, meta)
+
+	f.mu.Lock()
+	posters, ok := f.posters.Get(hash)
+	if !ok {
+		// The entry is added once so the window starts at the first sighting.
+		posters = make(map[string]struct{})
+		f.posters.Add(hash, posters)
+	}
+	// Growth stops one past the limit to bound memory per hash.
+	if len(posters) <= f.cfg.MaxDistinctPubkeys {
+		posters[event.PubKey] = struct{}{}
+	}
+	count := len(posters)
+	f.mu.Unlock()
+
+	if count > f.cfg.MaxDistinctPubkeys {
+		reason := fmt.Sprintf("content_posted_by_too_many_accounts:max_%d", f.cfg.MaxDistinctPubkeys)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "content_fanout_ok", nil)
+}
+
+// Close releases the filter's caches.
+func (f *ContentFanoutFilter) Close() error {
+	purgeCache(f.posters)
+	return nil
+}
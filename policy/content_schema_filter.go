@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	contentSchemaFilterName = "ContentSchemaFilter"
+)
+
+type schemaField struct {
+	name     string
+	wantType config.SchemaFieldType
+}
+
+type ContentSchemaFilter struct {
+	filterBase
+
+	cfg     *config.ContentSchemaFilterConfig
+	schemas map[int][]schemaField
+}
+
+func NewContentSchemaFilter(cfg *config.ContentSchemaFilterConfig) (*ContentSchemaFilter, error) {
+	warnDisabledWithRules(contentSchemaFilterName, cfg.Enabled, len(cfg.Schemas))
+	if !cfg.Enabled {
+		return &ContentSchemaFilter{cfg: cfg}, nil
+	}
+
+	schemas := make(map[int][]schemaField, len(cfg.Schemas))
+	for kind, schema := range cfg.Schemas {
+		fields := make([]schemaField, 0, len(schema.RequiredFields))
+		for name, wantType := range schema.RequiredFields {
+			switch wantType {
+			case config.SchemaFieldString, config.SchemaFieldNumber, config.SchemaFieldBoolean,
+				config.SchemaFieldObject, config.SchemaFieldArray, config.SchemaFieldAny, "":
+			default:
+				return nil, fmt.Errorf("invalid type %q for field '%s' in schema for kind %d", wantType, name, kind)
+			}
+			fields = append(fields, schemaField{name: name, wantType: wantType})
+		}
+		// Sorted so the reported field is stable across runs.
+		slices.SortFunc(fields, func(a, b schemaField) int {
+			return cmp.Compare(a.name, b.name)
+		})
+		schemas[kind] = fields
+	}
+
+	filter := &ContentSchemaFilter{
+		cfg:     cfg,
+		schemas: schemas,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ContentSchemaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(contentSchemaFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	fields, ok := f.schemas[event.Kind]
+	if !ok {
+		return newResult(true, "no_schema_for_kind", nil)
+	}
+
+	var content map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(event.Content), &content); err != nil || content == nil {
+		return newResult(false, "invalid_content_json", nil)
+	}
+
+	for _, field := range fields {
+		raw, present := content[field.name]
+		if !present {
+			return newResult(false, fmt.Sprintf("missing_content_field:'%s'", field.name), nil)
+		}
+		if field.wantType == "" || field.wantType == config.SchemaFieldAny {
+			continue
+		}
+		if got := jsonValueType(raw); got != field.wantType {
+			reason := fmt.Sprintf("invalid_content_field_type:'%s',want_%s,got_%s", field.name, field.wantType, got)
+			return newResult(false, reason, nil)
+		}
+	}
+
+	return newResult(true, "content_schema_ok", nil)
+}
+
+// jsonValueType classifies a raw JSON value by its first significant byte.
+// A JSON null is reported as "null", which matches no schema type.
+func jsonValueType(raw json.RawMessage) config.SchemaFieldType {
+	for _, c := range raw {
+		switch c {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case '"':
+			return config.SchemaFieldString
+		case '{':
+			return config.SchemaFieldObject
+		case '[':
+			return config.SchemaFieldArray
+		case 't', 'f':
+			return config.SchemaFieldBoolean
+		case 'n':
+			return "null"
+		default:
+			return config.SchemaFieldNumber
+		}
+	}
+	return "null"
+}
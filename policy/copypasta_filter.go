@@ -0,0 +1,116 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	copypastaFilterName = "CopypastaFilter"
+)
+
+// CopypastaFilter limits how often the same normalized content may be
+// posted network-wide: once it has been accepted MaxGlobalOccurrences times
+// within Window, further copies are rejected from any author. Unlike
+// ContentFanoutFilter it counts occurrences rather than distinct pubkeys,
+// so a single author repeating a message counts too.
+type CopypastaFilter struct {
+	filterBase
+
+	mu     sync.Mutex
+	cfg    *config.CopypastaFilterConfig
+	kinds  map[int]struct{}
+	counts *lru.LRU[string, *int]
+}
+
+func NewCopypastaFilter(cfg *config.CopypastaFilterConfig) (*CopypastaFilter, error) {
+	if !cfg.Enabled {
+		return &CopypastaFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	filter := &CopypastaFilter{
+		cfg:    cfg,
+		kinds:  kinds,
+		counts: lru.NewLRU[string, *int](size, nil, window),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *CopypastaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(copypastaFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxGlobalOccurrences <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	if f.cfg.MinContentLength > 0 {
+		if len(normalizeForHash(event.Content)) < f.cfg.MinContentLength {
+			return newResult(true, "content_too_short", nil)
+		}
+	}
+	if strings.TrimSpace(event.Content) == "" {
+		return newResult(true, "content_too_short", nil)
+	}
+	hash := contentHash(event, meta)
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(hash)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked checks and counts an occurrence of hash. The caller must hold f.mu.
+func (f *CopypastaFilter) evaluateLocked(hash string) (bool, string) {
+	count, ok := f.counts.Get(hash)
+	if !ok {
+		// The entry is added once so the window starts at the first sighting.
+		count = new(int)
+		f.counts.Add(hash, count)
+	}
+	if *count >= f.cfg.MaxGlobalOccurrences {
+		reason := fmt.Sprintf("content_posted_too_frequently:count_%d,max_%d", *count, f.cfg.MaxGlobalOccurrences)
+		return false, reason
+	}
+	*count++
+
+	return true, "content_frequency_ok"
+}
+
+// Close releases the counters.
+func (f *CopypastaFilter) Close() error {
+	purgeCache(f.counts)
+	return nil
+}
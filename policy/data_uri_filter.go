@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	dataURIFilterName = "DataURIFilter"
+)
+
+var dataURIRe = regexp.MustCompile(`(?i)\bdata:([^,\s]*),([^\s"'<>()]*)`)
+
+type DataURIFilter struct {
+	filterBase
+
+	cfg   *config.DataURIFilterConfig
+	kinds map[int]struct{}
+}
+
+func NewDataURIFilter(cfg *config.DataURIFilterConfig) (*DataURIFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &DataURIFilter{
+		cfg:   cfg,
+		kinds: kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// Match rejects content whose data: URIs are too many or whose payloads
+// together decode to more than MaxDataURIBytes.
+func (f *DataURIFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(dataURIFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	matches := dataURIRe.FindAllStringSubmatch(event.Content, -1)
+	if len(matches) == 0 {
+		return newResult(true, "no_data_uris", nil)
+	}
+	if f.cfg.MaxDataURIs > 0 && len(matches) > f.cfg.MaxDataURIs {
+		reason := fmt.Sprintf("too_many_data_uris:got_%d,max_%d", len(matches), f.cfg.MaxDataURIs)
+		return newResult(false, reason, nil)
+	}
+
+	if f.cfg.MaxDataURIBytes > 0 {
+		total := 0
+		for _, m := range matches {
+			total += dataURIDecodedSize(m[1], m[2])
+		}
+		if total > f.cfg.MaxDataURIBytes {
+			reason := fmt.Sprintf("data_uri_payload_too_large:size_%d,max_%d", total, f.cfg.MaxDataURIBytes)
+			return newResult(false, reason, nil)
+		}
+	}
+
+	return newResult(true, "data_uris_ok", nil)
+}
+
+// dataURIDecodedSize estimates the decoded size of a data: URI payload without
+// decoding it: base64 payloads shrink by a quarter, and each percent-escape in
+// other payloads stands for a single byte.
+func dataURIDecodedSize(header, payload string) int {
+	if strings.HasSuffix(strings.ToLower(header), ";base64") {
+		return len(strings.TrimRight(payload, "=")) * 3 / 4
+	}
+	return len(payload) - 2*strings.Count(payload, "%")
+}
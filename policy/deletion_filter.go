@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	deletionFilterName = "DeletionFilter"
+)
+
+// EventAuthorLookup returns the author pubkey of a stored event and whether
+// the event is known.
+type EventAuthorLookup func(id string) (string, bool)
+
+// DeletionFilter validates NIP-09 deletion requests (kind 5). A request must
+// name at least one well-formed `e` or `a` target, and every target must
+// belong to the requester: `a` coordinates carry their author, and `e` ids
+// are resolved through the lookup when one is given. With RequireOwnership,
+// ids the lookup does not know are rejected too.
+type DeletionFilter struct {
+	filterBase
+
+	cfg    *config.DeletionFilterConfig
+	lookup EventAuthorLookup
+}
+
+// NewDeletionFilter builds a DeletionFilter. lookup may be nil unless
+// RequireOwnership is set.
+func NewDeletionFilter(cfg *config.DeletionFilterConfig, lookup EventAuthorLookup) (*DeletionFilter, error) {
+	if cfg.Enabled && cfg.RequireOwnership && lookup == nil {
+		return nil, errors.New("deletion filter requires ownership but lookup is nil")
+	}
+
+	filter := &DeletionFilter{
+		cfg:    cfg,
+		lookup: lookup,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *DeletionFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(deletionFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if event.Kind != nostr.KindDeletion {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	targets := 0
+	for _, tag := range event.Tags {
+		if len(tag) == 0 || (tag[0] != "e" && tag[0] != "a") {
+			continue
+		}
+		targets++
+		if len(tag) < 2 {
+			return newResult(false, fmt.Sprintf("malformed_deletion_target:'%s'", tag[0]), nil)
+		}
+		value := tag[1]
+
+		if tag[0] == "a" {
+			author, ok := coordinateAuthor(value)
+			if !ok {
+				return newResult(false, fmt.Sprintf("malformed_deletion_target:'%s'", value), nil)
+			}
+			if author != event.PubKey {
+				return newResult(false, fmt.Sprintf("deletion_target_not_owned:'%s'", value), nil)
+			}
+			continue
+		}
+
+		if !nostr.IsValid32ByteHex(value) {
+			return newResult(false, fmt.Sprintf("malformed_deletion_target:'%s'", value), nil)
+		}
+		if f.lookup == nil {
+			continue
+		}
+		author, known := f.lookup(value)
+		switch {
+		case known && author != event.PubKey:
+			return newResult(false, fmt.Sprintf("deletion_target_not_owned:'%s'", value), nil)
+		case !known && f.cfg.RequireOwnership:
+			return newResult(false, fmt.Sprintf("deletion_target_unknown:'%s'", value), nil)
+		}
+	}
+
+	if targets == 0 {
+		return newResult(false, "missing_deletion_target", nil)
+	}
+	return newResult(true, "deletion_ok", nil)
+}
+
+// coordinateAuthor returns the pubkey of a `kind:pubkey:d-tag` coordinate.
+func coordinateAuthor(coordinate string) (string, bool) {
+	parts := strings.SplitN(coordinate, ":", 3)
+	if len(parts) != 3 {
+		return "", false
+	}
+	if _, err := strconv.Atoi(parts[0]); err != nil {
+		return "", false
+	}
+	if !nostr.IsValidPublicKey(parts[1]) {
+		return "", false
+	}
+	return parts[1], true
+}
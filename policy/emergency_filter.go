@@ -2,13 +2,16 @@ package policy
 
 import (
 	"context"
+	"math/rand/v2"
 	"net"
+	"time"
 
 	lru "github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/nbd-wtf/go-nostr"
 	"golang.org/x/time/rate"
 
 	"github.com/lessucettes/adresu-kit/config"
+	"github.com/lessucettes/adresu-kit/nip"
 )
 
 const (
@@ -16,8 +19,12 @@ const (
 )
 
 type EmergencyFilter struct {
-	newKeyLimiter *rate.Limiter
-	recentSeen    *lru.LRU[string, struct{}]
+	filterBase
+
+	newKeyLimiter  *rate.Limiter
+	recentSeen     *lru.LRU[string, struct{}]
+	powBypass      int
+	earlyDropStart float64
 
 	perIPEnabled  bool
 	perIPLimiters *lru.LRU[string, *rate.Limiter]
@@ -36,6 +43,10 @@ func NewEmergencyFilter(cfg *config.EmergencyFilterConfig) (*EmergencyFilter, er
 	filter := &EmergencyFilter{
 		newKeyLimiter: rate.NewLimiter(rate.Limit(cfg.NewKeysRate), cfg.NewKeysBurst),
 		recentSeen:    lru.NewLRU[string, struct{}](cfg.CacheSize, nil, cfg.TTL),
+		powBypass:     cfg.PoWBypassDifficulty,
+	}
+	if cfg.EarlyDropStart > 0 && cfg.EarlyDropStart < 1 && cfg.NewKeysBurst > 0 {
+		filter.earlyDropStart = cfg.EarlyDropStart
 	}
 
 	if cfg.PerIP.Enabled {
@@ -47,15 +58,19 @@ func NewEmergencyFilter(cfg *config.EmergencyFilterConfig) (*EmergencyFilter, er
 		filter.ipv6Prefix = cfg.PerIP.IPv6Prefix
 	}
 
+	filter.dryRun.Store(cfg.DryRun)
 	return filter, nil
 }
 
 func (f *EmergencyFilter) Match(_ context.Context, ev *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(emergencyFilterName)
+	newResult := f.resultFunc(emergencyFilterName, ev, meta)
 
 	if f.newKeyLimiter == nil {
 		return newResult(true, "filter_disabled", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
 	pk := ev.PubKey
 	if pk == "" {
@@ -75,20 +90,50 @@ func (f *EmergencyFilter) Match(_ context.Context, ev *nostr.Event, meta map[str
 				f.perIPLimiters.Add(key, lim)
 			}
 
-			if !lim.Allow() {
-				return newResult(false, "new_pubkey_rate_limit_exceeded_per_ip", nil)
+			if !lim.AllowN(f.now(), 1) {
+				return f.rejectOrBypass(newResult, ev, "new_pubkey_rate_limit_exceeded_per_ip")
 			}
 		}
 	}
 
-	if !f.newKeyLimiter.Allow() {
-		return newResult(false, "new_pubkey_rate_limit_exceeded_global", nil)
+	now := f.now()
+	if f.earlyDrop(now) {
+		return f.rejectOrBypass(newResult, ev, "new_pubkey_rate_limit_early_drop")
+	}
+	if !f.newKeyLimiter.AllowN(now, 1) {
+		return f.rejectOrBypass(newResult, ev, "new_pubkey_rate_limit_exceeded_global")
 	}
 
 	f.recentSeen.Add(pk, struct{}{})
 	return newResult(true, "new_pubkey_accepted", nil)
 }
 
+// rejectOrBypass admits a rate-limited new pubkey if the event carries enough
+// proof of work, and rejects it with reason otherwise.
+func (f *EmergencyFilter) rejectOrBypass(newResult func(bool, string, error, ...MessageData) (FilterResult, error), ev *nostr.Event, reason string) (FilterResult, error) {
+	if f.powBypass > 0 && nip.IsPoWValid(ev, f.powBypass) {
+		f.recentSeen.Add(ev.PubKey, struct{}{})
+		return newResult(true, "new_pubkey_admitted_by_pow", nil)
+	}
+	return newResult(false, reason, nil)
+}
+
+// earlyDrop reports whether a new pubkey should be dropped before the global
+// limiter is exhausted. Once the limiter's fill (spent share of its burst)
+// passes earlyDropStart, the drop probability rises linearly from 0 to 1 at
+// exhaustion, so admission degrades gradually instead of at a cliff.
+func (f *EmergencyFilter) earlyDrop(now time.Time) bool {
+	if f.earlyDropStart <= 0 {
+		return false
+	}
+	fill := 1 - f.newKeyLimiter.TokensAt(now)/float64(f.newKeyLimiter.Burst())
+	if fill <= f.earlyDropStart {
+		return false
+	}
+	p := (fill - f.earlyDropStart) / (1 - f.earlyDropStart)
+	return rand.Float64() < p
+}
+
 func normalizeIPWithOptionalPrefixes(ipStr string, v4Prefix, v6Prefix int) string {
 	ip := net.ParseIP(ipStr)
 	if ip == nil {
@@ -111,3 +156,10 @@ func normalizeIPWithOptionalPrefixes(ipStr string, v4Prefix, v6Prefix int) strin
 	}
 	return ip.String()
 }
+
+// Close releases the filter's caches.
+func (f *EmergencyFilter) Close() error {
+	purgeCache(f.recentSeen)
+	purgeCache(f.perIPLimiters)
+	return nil
+}
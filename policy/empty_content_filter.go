@@ -0,0 +1,67 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	emptyContentFilterName = "EmptyContentFilter"
+)
+
+// EmptyContentFilter rejects blank content for kinds that must carry some,
+// and any content for kinds that must be empty. Whitespace-only content
+// counts as empty.
+type EmptyContentFilter struct {
+	filterBase
+
+	cfg          *config.EmptyContentFilterConfig
+	requireText  map[int]struct{}
+	requireEmpty map[int]struct{}
+}
+
+func NewEmptyContentFilter(cfg *config.EmptyContentFilterConfig) (*EmptyContentFilter, error) {
+	requireText := make(map[int]struct{}, len(cfg.RequireContentKinds))
+	for _, k := range cfg.RequireContentKinds {
+		requireText[k] = struct{}{}
+	}
+	requireEmpty := make(map[int]struct{}, len(cfg.RequireEmptyKinds))
+	for _, k := range cfg.RequireEmptyKinds {
+		if _, ok := requireText[k]; ok {
+			return nil, fmt.Errorf("kind %d cannot both require and forbid content", k)
+		}
+		requireEmpty[k] = struct{}{}
+	}
+	warnDisabledWithRules(emptyContentFilterName, cfg.Enabled, len(requireText)+len(requireEmpty))
+
+	filter := &EmptyContentFilter{
+		cfg:          cfg,
+		requireText:  requireText,
+		requireEmpty: requireEmpty,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *EmptyContentFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(emptyContentFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+
+	empty := strings.TrimSpace(event.Content) == ""
+	if _, ok := f.requireText[event.Kind]; ok && empty {
+		return newResult(false, fmt.Sprintf("missing_content:kind_%d", event.Kind), nil)
+	}
+	if _, ok := f.requireEmpty[event.Kind]; ok && !empty {
+		return newResult(false, fmt.Sprintf("invalid_nonempty_content:kind_%d", event.Kind), nil)
+	}
+	return newResult(true, "content_presence_ok", nil)
+}
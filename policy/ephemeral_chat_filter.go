@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"slices"
+	"strings"
 	"time"
 	"unicode"
 
@@ -21,11 +22,22 @@ const (
 )
 
 type EphemeralChatFilter struct {
+	filterBase
+
 	cfg        *config.EphemeralChatFilterConfig
 	zalgoRegex *regexp.Regexp
 	wordRegex  *regexp.Regexp
 	lastSeen   *lru.LRU[string, time.Time]
 	limiters   *lru.LRU[string, *rate.Limiter]
+	graceBurst *lru.LRU[string, *rate.Limiter]
+	lastText   *lru.LRU[string, repeatedMessage]
+}
+
+// repeatedMessage is a pubkey's last message and how many times in a row it
+// has been sent.
+type repeatedMessage struct {
+	content string
+	count   int
 }
 
 func NewEphemeralChatFilter(cfg *config.EphemeralChatFilterConfig) (*EphemeralChatFilter, error) {
@@ -53,28 +65,48 @@ func NewEphemeralChatFilter(cfg *config.EphemeralChatFilterConfig) (*EphemeralCh
 	lastSeen := lru.NewLRU[string, time.Time](size, nil, 5*time.Minute)
 	limiters := lru.NewLRU[string, *rate.Limiter](size, nil, 15*time.Minute)
 
+	var graceBurst *lru.LRU[string, *rate.Limiter]
+	if cfg.MinDelay > 0 && cfg.MinDelayBurst > 0 {
+		graceBurst = lru.NewLRU[string, *rate.Limiter](size, nil, 15*time.Minute)
+	}
+
+	var lastText *lru.LRU[string, repeatedMessage]
+	if cfg.MaxConsecutiveIdentical > 0 {
+		lastText = lru.NewLRU[string, repeatedMessage](size, nil, 15*time.Minute)
+	}
+
 	filter := &EphemeralChatFilter{
 		cfg:        cfg,
 		zalgoRegex: zalgoRegex,
 		wordRegex:  wordRegex,
 		lastSeen:   lastSeen,
 		limiters:   limiters,
+		graceBurst: graceBurst,
+		lastText:   lastText,
 	}
 
+	filter.dryRun.Store(cfg.DryRun)
 	return filter, nil
 }
 
 func (f *EphemeralChatFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(ephemeralChatFilterName)
+	newResult := f.resultFunc(ephemeralChatFilterName, event, meta)
 
 	if !f.cfg.Enabled || !slices.Contains(f.cfg.Kinds, event.Kind) {
 		return newResult(true, "filter_disabled_or_kind_not_matched", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
+	now := f.now()
 	if f.lastSeen != nil && f.cfg.MinDelay > 0 {
-		now := time.Now()
 		if last, ok := f.lastSeen.Get(event.PubKey); ok {
-			if delay := now.Sub(last); delay < f.cfg.MinDelay {
+			delay := now.Sub(last)
+			if meta != nil {
+				meta["chat_delay_ms"] = delay.Milliseconds()
+			}
+			if delay < f.cfg.MinDelay && !f.allowGrace(event.PubKey, now) {
 				reason := fmt.Sprintf("posting_too_frequently:delay_%.1fs,limit_%.1fs", delay.Seconds(), f.cfg.MinDelay.Seconds())
 				return newResult(false, reason, nil)
 			}
@@ -84,29 +116,45 @@ func (f *EphemeralChatFilter) Match(_ context.Context, event *nostr.Event, meta
 
 	content := event.Content
 
-	if f.cfg.MaxCapsRatio > 0 {
-		letters, caps := 0, 0
-		for _, r := range content {
-			if unicode.IsLetter(r) {
-				letters++
-				if unicode.IsUpper(r) {
-					caps++
-				}
-			}
+	if f.lastText != nil {
+		last, _ := f.lastText.Get(event.PubKey)
+		if last.content == content {
+			last.count++
+		} else {
+			last = repeatedMessage{content: content, count: 1}
+		}
+		f.lastText.Add(event.PubKey, last)
+		if last.count > f.cfg.MaxConsecutiveIdentical {
+			reason := fmt.Sprintf("repeated_message:count_%d,max_%d", last.count, f.cfg.MaxConsecutiveIdentical)
+			return newResult(false, reason, nil)
 		}
+	}
+
+	// Content checks are skipped for very short messages; flood and rate
+	// limits still apply below.
+	checkContent := f.cfg.MinWordsForCheck <= 0 || len(strings.Fields(content)) >= f.cfg.MinWordsForCheck
+
+	if checkContent && f.cfg.MaxCapsRatio > 0 {
+		prose := content
+		if f.cfg.CapsIgnoreLinks {
+			// URLs, nostr references, hashtags and emails say nothing
+			// about shouting, so they are left out of the ratio.
+			prose = contentCleanerRegex.ReplaceAllString(content, "")
+		}
+		ratio, letters := capsRatio(prose)
 		minLetters := f.cfg.MinLettersForCapsCheck
 		if minLetters <= 0 {
 			minLetters = 20
 		}
 		if letters > minLetters {
-			if ratio := float64(caps) / float64(letters); ratio > f.cfg.MaxCapsRatio {
+			if ratio > f.cfg.MaxCapsRatio {
 				reason := fmt.Sprintf("excessive_caps:ratio_%.2f,limit_%.2f", ratio, f.cfg.MaxCapsRatio)
 				return newResult(false, reason, nil)
 			}
 		}
 	}
 
-	if f.cfg.MaxRepeatChars > 0 {
+	if checkContent && f.cfg.MaxRepeatChars > 0 {
 		runes := []rune(content)
 		if len(runes) >= f.cfg.MaxRepeatChars {
 			count := 1
@@ -124,16 +172,19 @@ func (f *EphemeralChatFilter) Match(_ context.Context, event *nostr.Event, meta
 		}
 	}
 
-	if f.wordRegex != nil && f.wordRegex.MatchString(content) {
+	if checkContent && f.wordRegex != nil && f.wordRegex.MatchString(content) {
 		return newResult(false, fmt.Sprintf("word_too_long:limit_%d", f.cfg.MaxWordLength), nil)
 	}
 
-	if f.zalgoRegex != nil && f.zalgoRegex.MatchString(content) {
+	if checkContent && f.zalgoRegex != nil && f.zalgoRegex.MatchString(content) {
 		return newResult(false, "zalgo_text_detected", nil)
 	}
 
 	limiter := f.getLimiter(event.PubKey)
-	if limiter.Allow() {
+	if limiter.AllowN(now, 1) {
+		if meta != nil {
+			meta["rate_tokens_remaining"] = limiter.TokensAt(now)
+		}
 		return newResult(true, "rate_limit_ok", nil)
 	}
 
@@ -145,6 +196,24 @@ func (f *EphemeralChatFilter) Match(_ context.Context, event *nostr.Event, meta
 	return newResult(false, reason, nil)
 }
 
+// capsRatio returns the share of uppercase letters among all letters in
+// content, along with the letter count.
+func capsRatio(content string) (float64, int) {
+	letters, caps := 0, 0
+	for _, r := range content {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				caps++
+			}
+		}
+	}
+	if letters == 0 {
+		return 0, 0
+	}
+	return float64(caps) / float64(letters), letters
+}
+
 func (f *EphemeralChatFilter) getLimiter(key string) *rate.Limiter {
 	if limiter, ok := f.limiters.Get(key); ok {
 		return limiter
@@ -153,3 +222,27 @@ func (f *EphemeralChatFilter) getLimiter(key string) *rate.Limiter {
 	f.limiters.Add(key, limiter)
 	return limiter
 }
+
+// allowGrace spends one token from the pubkey's grace bucket, which lets up
+// to MinDelayBurst messages through faster than MinDelay and refills at one
+// token per MinDelay.
+func (f *EphemeralChatFilter) allowGrace(key string, now time.Time) bool {
+	if f.graceBurst == nil {
+		return false
+	}
+	bucket, ok := f.graceBurst.Get(key)
+	if !ok {
+		bucket = rate.NewLimiter(rate.Every(f.cfg.MinDelay), f.cfg.MinDelayBurst)
+		f.graceBurst.Add(key, bucket)
+	}
+	return bucket.AllowN(now, 1)
+}
+
+// Close releases the filter's caches.
+func (f *EphemeralChatFilter) Close() error {
+	purgeCache(f.lastSeen)
+	purgeCache(f.limiters)
+	purgeCache(f.graceBurst)
+	purgeCache(f.lastText)
+	return nil
+}
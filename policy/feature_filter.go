@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	featureFilterName = "FeatureFilter"
+)
+
+var linkRe = regexp.MustCompile(`(?i)\b(?:https?|wss?)://\S+`)
+
+// FeatureExtractor computes numeric features of an event for an external
+// scorer.
+type FeatureExtractor func(ev *nostr.Event) map[string]float64
+
+// ExtractFeatures is the default FeatureExtractor. It returns caps_ratio,
+// emoji_ratio, link_count, entropy (Shannon, bits per rune), is_repost and
+// content_length (in runes).
+func ExtractFeatures(ev *nostr.Event) map[string]float64 {
+	content := ev.Content
+	length := utf8.RuneCountInString(content)
+	caps, _ := capsRatio(content)
+
+	var emoji float64
+	if length > 0 {
+		n := 0
+		for _, r := range content {
+			if unicode.Is(unicode.So, r) {
+				n++
+			}
+		}
+		emoji = float64(n) / float64(length)
+	}
+
+	var repost float64
+	if ev.Kind == nostr.KindRepost || ev.Kind == nostr.KindGenericRepost ||
+		(ev.Kind == nostr.KindTextNote && hasTag(ev, "q")) {
+		repost = 1
+	}
+
+	return map[string]float64{
+		"caps_ratio":     caps,
+		"emoji_ratio":    emoji,
+		"link_count":     float64(len(linkRe.FindAllStringIndex(content, -1))),
+		"entropy":        shannonEntropy(content),
+		"is_repost":      repost,
+		"content_length": float64(length),
+	}
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per rune.
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// FeatureFilter never rejects. It stores the extracted features in
+// meta["features"] for a downstream scorer.
+type FeatureFilter struct {
+	filterBase
+
+	cfg       *config.FeatureFilterConfig
+	extractor FeatureExtractor
+	kinds     map[int]struct{}
+}
+
+// NewFeatureFilter builds a FeatureFilter. A nil extractor uses
+// ExtractFeatures.
+func NewFeatureFilter(cfg *config.FeatureFilterConfig, extractor FeatureExtractor) (*FeatureFilter, error) {
+	if extractor == nil {
+		extractor = ExtractFeatures
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &FeatureFilter{
+		cfg:       cfg,
+		extractor: extractor,
+		kinds:     kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *FeatureFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(featureFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if meta == nil {
+		return newResult(true, "meta_missing", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	meta["features"] = f.extractor(event)
+	return newResult(true, "features_extracted", nil)
+}
@@ -0,0 +1,173 @@
+package policy
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DecisionHook is called with every decision a filter makes, on both the
+// accept and reject paths, just before Match returns. It runs synchronously
+// on the Match goroutine, after the filter has released its locks, so a slow
+// hook delays the caller but never blocks other Match calls.
+type DecisionHook func(ev *nostr.Event, res FilterResult, err error, meta map[string]any)
+
+// DecisionHookFunc adapts a hook that takes the filter name and verdict as
+// plain arguments to a DecisionHook.
+func DecisionHookFunc(fn func(filter string, ev *nostr.Event, accepted bool, err error, meta map[string]any)) DecisionHook {
+	if fn == nil {
+		return nil
+	}
+	return func(ev *nostr.Event, res FilterResult, err error, meta map[string]any) {
+		fn(res.Filter, ev, res.Allowed, err, meta)
+	}
+}
+
+// filterBase carries behavior shared by all filters. It is embedded in every
+// filter struct and is safe to use as a zero value.
+type filterBase struct {
+	onDecision atomic.Pointer[DecisionHook]
+	dryRun     atomic.Bool
+	messages   atomic.Pointer[messageTemplates]
+	clock      atomic.Pointer[Clock]
+}
+
+// SetOnDecision installs a hook invoked for every decision. Passing nil
+// removes it.
+func (b *filterBase) SetOnDecision(hook DecisionHook) {
+	if hook == nil {
+		b.onDecision.Store(nil)
+		return
+	}
+	b.onDecision.Store(&hook)
+}
+
+// resultFunc wraps NewResultFunc so that the resulting decision is reported
+// to the installed hook. In dry-run mode a rejection without an error is
+// turned into an accept, and the would-be decision is recorded in
+// meta["would_block"] and meta["would_block_reason"]. The first filter to
+// record a would-be rejection wins.
+//
+// Rejections are rendered through the filter's message templates, if any.
+// data fills the template's Limit, Got and Rule; Kind is taken from ev.
+func (b *filterBase) resultFunc(filterName string, ev *nostr.Event, meta map[string]any) func(allowed bool, reason string, err error, data ...MessageData) (FilterResult, error) {
+	newResult := NewResultFunc(filterName)
+	hook := b.onDecision.Load()
+	dryRun := b.dryRun.Load()
+	messages := b.messages.Load()
+	return func(allowed bool, reason string, err error, data ...MessageData) (FilterResult, error) {
+		if dryRun && !allowed && err == nil {
+			if meta != nil {
+				if _, recorded := meta["would_block"]; !recorded {
+					meta["would_block"] = true
+					meta["would_block_reason"] = reason
+				}
+			}
+			allowed, reason = true, "dry_run:"+reason
+		}
+		res, err := newResult(allowed, reason, err)
+		if !res.Allowed && messages != nil {
+			var d MessageData
+			if len(data) > 0 {
+				d = data[0]
+			}
+			d.Kind = ev.Kind
+			res.Message = messages.render(reason, d)
+		}
+		if hook != nil {
+			(*hook)(ev, res, err, meta)
+		}
+		return res, err
+	}
+}
+
+// warnDisabledWithRules logs a warning when a filter is configured with rules
+// but not enabled, so that dead config does not go unnoticed.
+func warnDisabledWithRules(filterName string, enabled bool, ruleCount int) {
+	logWarnings(disabledWithRulesWarning(filterName, enabled, ruleCount))
+}
+
+// disabledWithRulesWarning returns the warning warnDisabledWithRules logs, or
+// nil when there is nothing to warn about. Reloadable filters return it from
+// Reload instead of logging it.
+func disabledWithRulesWarning(filterName string, enabled bool, ruleCount int) []string {
+	if !enabled && ruleCount > 0 {
+		return []string{fmt.Sprintf("%s config warning: %d rules are configured but the filter is disabled", filterName, ruleCount)}
+	}
+	return nil
+}
+
+// logWarnings logs config warnings, as constructors do with the warnings
+// returned by Reload.
+func logWarnings(warnings []string) {
+	for _, warning := range warnings {
+		slog.Warn(warning)
+	}
+}
+
+// SetClock replaces the time source used for the filter's time-dependent
+// decisions and state, such as rate buckets and activity windows. Passing
+// nil restores SystemClock.
+func (b *filterBase) SetClock(clock Clock) {
+	if clock == nil {
+		b.clock.Store(nil)
+		return
+	}
+	b.clock.Store(&clock)
+}
+
+// now returns the current time from the filter's clock.
+func (b *filterBase) now() time.Time {
+	if clock := b.clock.Load(); clock != nil {
+		return (*clock).Now()
+	}
+	return SystemClock.Now()
+}
+
+// ruleLabel names a configured rule for meta["matched_rule"]: its
+// description, or "rule-<index>" when it has none.
+func ruleLabel(description string, index int) string {
+	if description != "" {
+		return description
+	}
+	return "rule-" + strconv.Itoa(index)
+}
+
+// setMatchedRule records which rule a rule-based filter applied.
+func setMatchedRule(meta map[string]any, label string) {
+	if meta != nil {
+		meta["matched_rule"] = label
+	}
+}
+
+// setMessages installs the rejection message templates.
+func (b *filterBase) setMessages(messages messageTemplates) {
+	if messages == nil {
+		b.messages.Store(nil)
+		return
+	}
+	b.messages.Store(&messages)
+}
+
+// Close releases resources held by the filter. Filters without state inherit
+// this no-op; stateful filters override it. Every Close in this package is
+// safe to call more than once and concurrently with Match, which keeps
+// working on empty state. Filters that persist state (RepostAbuseFilter)
+// save it under their lock, and only on the first successful Close.
+func (b *filterBase) Close() error {
+	return nil
+}
+
+// purgeCache empties cache if it was allocated. The expirable LRU's
+// background expiry goroutine cannot be stopped, but purging releases
+// everything it holds.
+func purgeCache[K comparable, V any](cache *lru.LRU[K, V]) {
+	if cache != nil {
+		cache.Purge()
+	}
+}
@@ -3,6 +3,7 @@ package policy
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -17,56 +18,129 @@ const (
 type timeLimits struct {
 	MaxPast   time.Duration
 	MaxFuture time.Duration
+	label     string
+}
+
+type freshnessRuleSet struct {
+	enabled      bool
+	defaults     timeLimits
+	rulesByKind  map[int]timeLimits
+	strictFuture bool
+	skew         time.Duration
+	useReceived  bool
 }
 
 type FreshnessFilter struct {
-	cfg         *config.FreshnessFilterConfig
-	rulesByKind map[int]timeLimits
+	filterBase
+
+	rules atomic.Pointer[freshnessRuleSet]
 }
 
 func NewFreshnessFilter(cfg *config.FreshnessFilterConfig) (*FreshnessFilter, error) {
-	rulesByKind := make(map[int]timeLimits)
+	filter := &FreshnessFilter{}
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+	return filter, nil
+}
 
+// Reload atomically replaces the time limits and returns the config warnings
+// that the constructor logs. The filter holds no other state.
+func (f *FreshnessFilter) Reload(cfg *config.FreshnessFilterConfig) ([]string, error) {
+	rules, warnings := compileFreshnessRules(cfg)
+	f.rules.Store(rules)
+	f.dryRun.Store(cfg != nil && cfg.DryRun)
+	return warnings, nil
+}
+
+func compileFreshnessRules(cfg *config.FreshnessFilterConfig) (*freshnessRuleSet, []string) {
+	rules := &freshnessRuleSet{rulesByKind: make(map[int]timeLimits)}
+
+	var warnings []string
 	if cfg != nil {
-		for _, rule := range cfg.Rules {
+		// Default limits count as rules: configs written before the Enabled
+		// gate existed may set nothing else.
+		configured := len(cfg.Rules)
+		for _, set := range []bool{cfg.DefaultMaxPast != 0, cfg.DefaultMaxFuture != 0, cfg.StrictFuture} {
+			if set {
+				configured++
+			}
+		}
+		warnings = disabledWithRulesWarning(freshnessFilterName, cfg.Enabled, configured)
+		rules.enabled = cfg.Enabled
+		rules.defaults = timeLimits{
+			MaxPast:   cfg.DefaultMaxPast,
+			MaxFuture: cfg.DefaultMaxFuture,
+		}
+		rules.strictFuture = cfg.StrictFuture
+		rules.skew = cfg.ClockSkewTolerance
+		rules.useReceived = cfg.UseReceivedTime
+		for i, rule := range cfg.Rules {
 			limits := timeLimits{
 				MaxPast:   rule.MaxPast,
 				MaxFuture: rule.MaxFuture,
+				label:     ruleLabel(rule.Description, i),
 			}
 			for _, kind := range rule.Kinds {
-				rulesByKind[kind] = limits
+				rules.rulesByKind[kind] = limits
 			}
 		}
 	}
 
-	filter := &FreshnessFilter{
-		cfg:         cfg,
-		rulesByKind: rulesByKind,
-	}
-
-	return filter, nil
+	return rules, warnings
 }
 
 func (f *FreshnessFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(freshnessFilterName)
+	newResult := f.resultFunc(freshnessFilterName, event, meta)
+	rules := f.rules.Load()
+
+	if !rules.enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
-	maxPast, maxFuture := f.cfg.DefaultMaxPast, f.cfg.DefaultMaxFuture
+	maxPast, maxFuture := rules.defaults.MaxPast, rules.defaults.MaxFuture
 
-	if limits, ok := f.rulesByKind[event.Kind]; ok {
+	if limits, ok := rules.rulesByKind[event.Kind]; ok {
 		maxPast = limits.MaxPast
 		maxFuture = limits.MaxFuture
+		setMatchedRule(meta, limits.label)
+	} else {
+		setMatchedRule(meta, "default")
 	}
 
-	now := time.Now()
+	now := f.now()
 	createdAt := event.CreatedAt.Time()
+	if meta != nil {
+		meta["created_at_offset_seconds"] = int64(createdAt.Sub(now) / time.Second)
+	}
 
+	// With UseReceivedTime, age is measured from when the relay received the
+	// event, so historical imports are not rejected for their old created_at.
 	age := now.Sub(createdAt)
+	if rules.useReceived {
+		if receivedAt, ok := meta["received_at"].(time.Time); ok {
+			age = now.Sub(receivedAt)
+		}
+	}
 	if maxPast > 0 && age > maxPast {
 		reason := fmt.Sprintf("event_too_old:age_%s,max_%s", age.Round(time.Second), maxPast)
 		return newResult(false, reason, nil)
 	}
 
 	futureOffset := createdAt.Sub(now)
+
+	// Strict mode caps every kind at the clock skew tolerance, so far-future
+	// timestamps cannot pin replaceable events regardless of per-kind rules.
+	if rules.strictFuture && futureOffset > rules.skew {
+		reason := fmt.Sprintf("event_in_future:offset_%s,max_%s", futureOffset.Round(time.Second), rules.skew)
+		return newResult(false, reason, nil)
+	}
+
 	if maxFuture > 0 && futureOffset > maxFuture {
 		reason := fmt.Sprintf("event_in_future:offset_%s,max_%s", futureOffset.Round(time.Second), maxFuture)
 		return newResult(false, reason, nil)
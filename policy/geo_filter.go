@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	geoFilterName = "GeoFilter"
+)
+
+// GeoResolver maps an IP address to an ISO 3166-1 alpha-2 country code.
+// It returns false when the address cannot be classified.
+type GeoResolver func(ip net.IP) (string, bool)
+
+type GeoFilter struct {
+	filterBase
+
+	cfg      *config.GeoFilterConfig
+	resolver GeoResolver
+	allowed  map[string]struct{}
+	denied   map[string]struct{}
+}
+
+func NewGeoFilter(cfg *config.GeoFilterConfig, resolver GeoResolver) (*GeoFilter, error) {
+	warnDisabledWithRules(geoFilterName, cfg.Enabled, len(cfg.AllowedCountries)+len(cfg.DeniedCountries))
+	if !cfg.Enabled {
+		return &GeoFilter{cfg: cfg}, nil
+	}
+	if resolver == nil {
+		return nil, errors.New("geo filter enabled but resolver is nil")
+	}
+
+	filter := &GeoFilter{
+		cfg:      cfg,
+		resolver: resolver,
+		allowed:  countrySet(cfg.AllowedCountries),
+		denied:   countrySet(cfg.DeniedCountries),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *GeoFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(geoFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	var country string
+	var resolved bool
+	if remoteIP, ok := meta["remote_ip"].(string); ok && remoteIP != "" {
+		if ip := net.ParseIP(remoteIP); ip != nil {
+			country, resolved = f.resolver(ip)
+			country = strings.ToUpper(country)
+		}
+	}
+
+	if !resolved || country == "" {
+		if f.cfg.AllowUnknown {
+			return newResult(true, "region_unknown_allowed", nil)
+		}
+		return newResult(false, "region_unknown", nil)
+	}
+
+	if meta != nil {
+		meta["country"] = country
+	}
+
+	if _, isDenied := f.denied[country]; isDenied {
+		return newResult(false, fmt.Sprintf("region_not_permitted:'%s'", country), nil)
+	}
+	if len(f.allowed) > 0 {
+		if _, isAllowed := f.allowed[country]; !isAllowed {
+			return newResult(false, fmt.Sprintf("region_not_permitted:'%s'", country), nil)
+		}
+	}
+
+	return newResult(true, fmt.Sprintf("region_allowed:'%s'", country), nil)
+}
+
+func countrySet(codes []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(codes))
+	for _, c := range codes {
+		set[strings.ToUpper(strings.TrimSpace(c))] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	hashtagFilterName = "HashtagFilter"
+)
+
+// HashtagFilter validates `t` tags and limits how many distinct hashtags an
+// event carries. Hashtags are compared lowercased, so `#Bitcoin` and
+// `#bitcoin` count once.
+type HashtagFilter struct {
+	filterBase
+
+	cfg   *config.HashtagFilterConfig
+	kinds map[int]struct{}
+}
+
+func NewHashtagFilter(cfg *config.HashtagFilterConfig) (*HashtagFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &HashtagFilter{
+		cfg:   cfg,
+		kinds: kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *HashtagFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(hashtagFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	hashtags := make(map[string]struct{})
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "t" {
+			continue
+		}
+		value := tag[1]
+		if value == "" || strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+			return newResult(false, fmt.Sprintf("malformed_hashtag:'%s'", value), nil)
+		}
+		if f.cfg.MaxHashtagLength > 0 && utf8.RuneCountInString(value) > f.cfg.MaxHashtagLength {
+			reason := fmt.Sprintf("hashtag_too_long:'%s',max_%d", value, f.cfg.MaxHashtagLength)
+			return newResult(false, reason, nil)
+		}
+		hashtags[strings.ToLower(value)] = struct{}{}
+	}
+
+	if f.cfg.MaxHashtags > 0 && len(hashtags) > f.cfg.MaxHashtags {
+		reason := fmt.Sprintf("too_many_hashtags:got_%d,max_%d", len(hashtags), f.cfg.MaxHashtags)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "hashtags_ok", nil)
+}
@@ -2,6 +2,8 @@ package policy
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
@@ -13,6 +15,9 @@ type FilterResult struct {
 	Filter   string
 	Reason   string
 	Duration time.Duration
+	// Message is an operator-configured, human-readable rejection message.
+	// It is empty unless a template matches the reason code.
+	Message string
 }
 
 // Filter is the interface that all kit filters must implement.
@@ -20,6 +25,33 @@ type Filter interface {
 	Match(ctx context.Context, ev *nostr.Event, meta map[string]any) (FilterResult, error)
 }
 
+// BatchFilter is implemented by filters that can evaluate a slice of events
+// more efficiently than sequential Match calls. Implementations must apply
+// state updates in slice order.
+type BatchFilter interface {
+	MatchBatch(ctx context.Context, events []*nostr.Event, metas []map[string]any) ([]FilterResult, []error)
+}
+
+// MatchBatch evaluates events against f, delegating to f.MatchBatch when the
+// filter implements BatchFilter and looping over Match otherwise. metas may be
+// nil or shorter than events; missing entries are passed as nil.
+func MatchBatch(ctx context.Context, f Filter, events []*nostr.Event, metas []map[string]any) ([]FilterResult, []error) {
+	if bf, ok := f.(BatchFilter); ok {
+		return bf.MatchBatch(ctx, events, metas)
+	}
+
+	results := make([]FilterResult, len(events))
+	errs := make([]error, len(events))
+	for i, ev := range events {
+		var meta map[string]any
+		if i < len(metas) {
+			meta = metas[i]
+		}
+		results[i], errs[i] = f.Match(ctx, ev, meta)
+	}
+	return results, errs
+}
+
 // NewResultFunc returns a helper function for creating FilterResult objects.
 func NewResultFunc(filterName string) func(allowed bool, reason string, err error) (FilterResult, error) {
 	start := time.Now()
@@ -32,3 +64,17 @@ func NewResultFunc(filterName string) func(allowed bool, reason string, err erro
 		}, err
 	}
 }
+
+// CloseAll closes every filter that implements io.Closer and joins the
+// errors.
+func CloseAll(filters ...Filter) error {
+	var errs []error
+	for _, f := range filters {
+		if closer, ok := f.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	ipReputationFilterName = "IPReputationFilter"
+)
+
+type IPReputationFilter struct {
+	filterBase
+
+	mu     sync.RWMutex
+	cfg    *config.IPReputationFilterConfig
+	denied []*net.IPNet
+}
+
+func NewIPReputationFilter(cfg *config.IPReputationFilterConfig) (*IPReputationFilter, error) {
+	warnDisabledWithRules(ipReputationFilterName, cfg.Enabled, len(cfg.DeniedCIDRs))
+
+	filter := &IPReputationFilter{cfg: cfg}
+	if err := filter.Update(cfg.DeniedCIDRs); err != nil {
+		return nil, err
+	}
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// Update replaces the denylist. Entries may be CIDR ranges or bare addresses.
+// On error the previous list stays active.
+func (f *IPReputationFilter) Update(cidrs []string) error {
+	denied := make([]*net.IPNet, 0, len(cidrs))
+	for _, entry := range cidrs {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return fmt.Errorf("invalid denylist address %q", entry)
+			}
+			if v4 := ip.To4(); v4 != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return fmt.Errorf("invalid denylist CIDR %q: %w", entry, err)
+		}
+		denied = append(denied, ipNet)
+	}
+
+	f.mu.Lock()
+	f.denied = denied
+	f.mu.Unlock()
+	return nil
+}
+
+func (f *IPReputationFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(ipReputationFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	remoteIP, _ := meta["remote_ip"].(string)
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return newResult(true, "remote_ip_unknown", nil)
+	}
+
+	// The denylist is swapped as a whole, so the snapshot can be read unlocked.
+	f.mu.RLock()
+	denied := f.denied
+	f.mu.RUnlock()
+
+	for _, ipNet := range denied {
+		if ipNet.Contains(ip) {
+			return newResult(false, "source_ip_denylisted", nil)
+		}
+	}
+
+	return newResult(true, "source_ip_ok", nil)
+}
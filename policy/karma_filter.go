@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	karmaFilterName = "KarmaFilter"
+)
+
+// KarmaLookup returns the reputation score of pubkey and whether it is known.
+type KarmaLookup func(pubkey string) (int, bool)
+
+type karmaEntry struct {
+	karma int
+	known bool
+}
+
+// KarmaFilter rejects configured kinds from pubkeys whose externally managed
+// reputation is below MinKarma. Lookups, including unknown results, are
+// cached for CacheTTL.
+type KarmaFilter struct {
+	filterBase
+
+	cfg    *config.KarmaFilterConfig
+	lookup KarmaLookup
+	kinds  map[int]struct{}
+	cache  *lru.LRU[string, karmaEntry]
+}
+
+func NewKarmaFilter(cfg *config.KarmaFilterConfig, lookup KarmaLookup) (*KarmaFilter, error) {
+	if !cfg.Enabled {
+		return &KarmaFilter{cfg: cfg}, nil
+	}
+	if lookup == nil {
+		return nil, errors.New("karma filter enabled but lookup is nil")
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+
+	filter := &KarmaFilter{
+		cfg:    cfg,
+		lookup: lookup,
+		kinds:  kinds,
+		cache:  lru.NewLRU[string, karmaEntry](size, nil, ttl),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *KarmaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(karmaFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	entry, ok := f.cache.Get(event.PubKey)
+	if !ok {
+		entry.karma, entry.known = f.lookup(event.PubKey)
+		f.cache.Add(event.PubKey, entry)
+	}
+
+	if !entry.known {
+		if f.cfg.RejectUnknown {
+			return newResult(false, "karma_unknown", nil)
+		}
+		return newResult(true, "karma_unknown_accepted", nil)
+	}
+	if meta != nil {
+		meta["karma"] = entry.karma
+	}
+	if entry.karma < f.cfg.MinKarma {
+		reason := fmt.Sprintf("karma_below_threshold:karma_%d,min_%d", entry.karma, f.cfg.MinKarma)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "karma_ok", nil)
+}
+
+// Close releases the filter's caches.
+func (f *KarmaFilter) Close() error {
+	purgeCache(f.cache)
+	return nil
+}
@@ -4,6 +4,11 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/nbd-wtf/go-nostr"
 
@@ -17,81 +22,380 @@ const (
 type compiledKeywordRule struct {
 	source      string
 	description string
+	severity    config.KeywordSeverity
 	regex       *regexp.Regexp
+	prep        contentPrep
+
+	// Fuzzy rules match content words within maxDistance edits of keyword
+	// instead of using regex.
+	keyword     []rune
+	maxDistance int
+}
+
+var (
+	fencedCodeRe = regexp.MustCompile("(?s)```.*?```")
+	inlineCodeRe = regexp.MustCompile("`[^`\n]*`")
+	quoteLineRe  = regexp.MustCompile(`(?m)^[ \t]*>.*$`)
+)
+
+// contentPrep describes how content is transformed before a rule sees it.
+// Rules sharing a contentPrep share the prepared input.
+type contentPrep struct {
+	stripCode   bool
+	stripQuotes bool
+	fold        bool
+}
+
+// apply returns a copy of content with markdown code and quotes removed and
+// diacritics folded, as configured.
+func (p contentPrep) apply(content string) string {
+	if p.stripCode {
+		content = fencedCodeRe.ReplaceAllString(content, " ")
+		content = inlineCodeRe.ReplaceAllString(content, " ")
+	}
+	if p.stripQuotes {
+		content = quoteLineRe.ReplaceAllString(content, "")
+	}
+	if p.fold {
+		content = foldDiacritics(content)
+	}
+	return content
+}
+
+// scanInput is content prepared for matching. Words are tokenized at most
+// once, and only if a fuzzy rule needs them.
+type scanInput struct {
+	content string
+	words   []string
+}
+
+func (in *scanInput) tokens() []string {
+	if in.words == nil {
+		in.words = strings.FieldsFunc(strings.ToLower(in.content), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+	}
+	return in.words
+}
+
+// match reports whether the rule matches and returns a quoted label for the
+// rejection reason.
+func (r *compiledKeywordRule) match(in *scanInput) (string, bool) {
+	if r.regex != nil {
+		if r.regex.MatchString(in.content) {
+			return "'" + r.source + "'", true
+		}
+		return "", false
+	}
+
+	for _, word := range in.tokens() {
+		w := []rune(word)
+		// Words whose length differs by more than the distance cannot match.
+		if diff := len(w) - len(r.keyword); diff > r.maxDistance || -diff > r.maxDistance {
+			continue
+		}
+		if levenshtein(w, r.keyword) <= r.maxDistance {
+			return fmt.Sprintf("'%s'~'%s'", word, r.source), true
+		}
+	}
+	return "", false
+}
+
+// requiredKeywordRule holds the patterns of a require-mode rule; content must
+// match at least one of them.
+type requiredKeywordRule struct {
+	description string
+	patterns    []compiledKeywordRule
+}
+
+type keywordRuleSet struct {
+	enabled      bool
+	kindToRules  map[int][]compiledKeywordRule
+	kindToReqs   map[int][]requiredKeywordRule
+	maxScanBytes int
+	onTimeout    config.TimeoutAction
 }
 
 type KeywordFilter struct {
-	enabled     bool
-	kindToRules map[int][]compiledKeywordRule
+	filterBase
+
+	rules atomic.Pointer[keywordRuleSet]
 }
 
 func NewKeywordFilter(cfg *config.KeywordFilterConfig) (*KeywordFilter, error) {
+	filter := &KeywordFilter{}
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+	return filter, nil
+}
+
+// Reload compiles cfg, including its message templates, atomically swaps it
+// in and returns the config warnings that the constructor logs. On error the
+// previous rules stay active. The filter holds no other state.
+func (f *KeywordFilter) Reload(cfg *config.KeywordFilterConfig) ([]string, error) {
+	rules, warnings, err := compileKeywordRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := compileMessages(keywordFilterName, cfg.Messages)
+	if err != nil {
+		return nil, err
+	}
+	f.rules.Store(rules)
+	f.setMessages(messages)
+	f.dryRun.Store(cfg.DryRun)
+	return warnings, nil
+}
+
+func compileKeywordRules(cfg *config.KeywordFilterConfig) (*keywordRuleSet, []string, error) {
+	warnings := disabledWithRulesWarning(keywordFilterName, cfg.Enabled, len(cfg.Rules))
 	if !cfg.Enabled {
-		return &KeywordFilter{enabled: false}, nil
+		return &keywordRuleSet{enabled: false}, warnings, nil
 	}
 
 	kindMap := make(map[int][]compiledKeywordRule)
+	requireMap := make(map[int][]requiredKeywordRule)
 
 	for _, rule := range cfg.Rules {
-		// Compile simple words into case-insensitive whole-word regexes.
+		severity := rule.Severity
+		switch severity {
+		case "":
+			severity = config.KeywordSeverityBlock
+		case config.KeywordSeverityBlock, config.KeywordSeverityFlag, config.KeywordSeverityShadow:
+		default:
+			return nil, nil, fmt.Errorf("invalid severity %q for rule '%s'", severity, rule.Description)
+		}
+
+		mode := rule.Mode
+		switch mode {
+		case "":
+			mode = config.KeywordModeDeny
+		case config.KeywordModeDeny, config.KeywordModeRequire:
+		default:
+			return nil, nil, fmt.Errorf("invalid mode %q for rule '%s'", mode, rule.Description)
+		}
+
+		var patterns []compiledKeywordRule
+
+		// Compile simple words into case-insensitive whole-word regexes, or
+		// into fuzzy rules when an edit distance is configured.
+		// With FoldDiacritics, patterns are folded here and content is
+		// folded before matching; labels keep the original keyword.
+		// IgnoreCodeBlocks and IgnoreQuotes match against a copy of the
+		// content with markdown code or "> " quote lines removed.
+		prep := contentPrep{
+			stripCode:   rule.IgnoreCodeBlocks,
+			stripQuotes: rule.IgnoreQuotes,
+			fold:        rule.FoldDiacritics,
+		}
+		fold := func(s string) string {
+			if rule.FoldDiacritics {
+				return foldDiacritics(s)
+			}
+			return s
+		}
+
 		for _, word := range rule.Words {
-			compiled, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+			if rule.MaxEditDistance > 0 {
+				patterns = append(patterns, compiledKeywordRule{
+					source:      word,
+					description: rule.Description,
+					severity:    severity,
+					prep:        prep,
+					keyword:     []rune(strings.ToLower(fold(word))),
+					maxDistance: rule.MaxEditDistance,
+				})
+				continue
+			}
+
+			compiled, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(fold(word)) + `\b`)
 			if err != nil {
-				return nil, fmt.Errorf("internal error compiling keyword '%s': %w", word, err)
+				return nil, nil, fmt.Errorf("internal error compiling keyword '%s': %w", word, err)
 			}
-			ckr := compiledKeywordRule{
+			patterns = append(patterns, compiledKeywordRule{
 				source:      word,
 				description: rule.Description,
+				severity:    severity,
 				regex:       compiled,
-			}
-			for _, kind := range rule.Kinds {
-				kindMap[kind] = append(kindMap[kind], ckr)
-			}
+				prep:        prep,
+			})
 		}
 
 		// Compile user-provided regexes as they are.
 		for _, rx := range rule.Regexps {
-			compiled, err := regexp.Compile(rx)
+			compiled, err := regexp.Compile(fold(rx))
 			if err != nil {
-				return nil, fmt.Errorf("failed to compile user regexp '%s' for rule '%s': %w", rx, rule.Description, err)
+				return nil, nil, fmt.Errorf("failed to compile user regexp '%s' for rule '%s': %w", rx, rule.Description, err)
 			}
-			ckr := compiledKeywordRule{
+			patterns = append(patterns, compiledKeywordRule{
 				source:      rx,
 				description: rule.Description,
+				severity:    severity,
 				regex:       compiled,
+				prep:        prep,
+			})
+		}
+
+		if mode == config.KeywordModeRequire {
+			if len(patterns) == 0 {
+				return nil, nil, fmt.Errorf("require rule '%s' has no words or regexps", rule.Description)
 			}
+			req := requiredKeywordRule{description: rule.Description, patterns: patterns}
 			for _, kind := range rule.Kinds {
-				kindMap[kind] = append(kindMap[kind], ckr)
+				requireMap[kind] = append(requireMap[kind], req)
 			}
+			continue
+		}
+		for _, kind := range rule.Kinds {
+			kindMap[kind] = append(kindMap[kind], patterns...)
 		}
 	}
 
-	filter := &KeywordFilter{
-		enabled:     cfg.Enabled,
-		kindToRules: kindMap,
+	rules := &keywordRuleSet{
+		enabled:      cfg.Enabled,
+		kindToRules:  kindMap,
+		kindToReqs:   requireMap,
+		maxScanBytes: cfg.MaxScanBytes,
+		onTimeout:    cfg.OnTimeout,
 	}
 
-	return filter, nil
+	return rules, warnings, nil
 }
 
-func (f *KeywordFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(keywordFilterName)
+// Match scans at most MaxScanBytes of content, so patterns appearing past the
+// limit are not detected. If ctx is done before all rules have run, the
+// OnTimeout action decides the result.
+func (f *KeywordFilter) Match(ctx context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(keywordFilterName, event, meta)
+	ruleSet := f.rules.Load()
 
-	if !f.enabled {
+	if !ruleSet.enabled {
 		return newResult(true, "filter_disabled", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
-	rules, exists := f.kindToRules[event.Kind]
-	if !exists {
+	rules, exists := ruleSet.kindToRules[event.Kind]
+	required, hasRequired := ruleSet.kindToReqs[event.Kind]
+	if !exists && !hasRequired {
 		return newResult(true, "no_rules_for_kind", nil)
 	}
 
-	for _, rule := range rules {
-		if rule.regex.MatchString(event.Content) {
-			reason := fmt.Sprintf("forbidden_pattern_found:'%s'", rule.source)
-			return newResult(false, reason, nil)
+	// All rules are evaluated so that flag and shadow matches are recorded
+	// even when a later rule blocks the event.
+	var blockedBy, shadowedBy, flaggedBy string
+	var flags []string
+
+	content := truncateUTF8(event.Content, ruleSet.maxScanBytes)
+
+	// Each variant of the content (stripped, folded) is computed at most
+	// once, and only if a rule needs it.
+	inputs := map[contentPrep]*scanInput{{}: {content: content}}
+	inputFor := func(rule *compiledKeywordRule) *scanInput {
+		in, ok := inputs[rule.prep]
+		if !ok {
+			in = &scanInput{content: rule.prep.apply(content)}
+			inputs[rule.prep] = in
+		}
+		return in
+	}
+
+	for i := range rules {
+		if ctx.Err() != nil {
+			return newResult(ruleSet.onTimeout != config.TimeoutReject, "keyword_scan_timeout", nil)
+		}
+		rule := &rules[i]
+		label, ok := rule.match(inputFor(rule))
+		if !ok {
+			continue
+		}
+		switch rule.severity {
+		case config.KeywordSeverityBlock:
+			if blockedBy == "" {
+				blockedBy = label
+			}
+		case config.KeywordSeverityShadow:
+			if shadowedBy == "" {
+				shadowedBy = label
+			}
+		case config.KeywordSeverityFlag:
+			if flaggedBy == "" {
+				flaggedBy = label
+			}
+			flags = append(flags, rule.source)
+		}
+	}
+
+	if meta != nil {
+		if len(flags) > 0 {
+			existing, _ := meta["flags"].([]string)
+			meta["flags"] = append(existing, flags...)
 		}
+		if shadowedBy != "" {
+			meta["shadow_banned"] = true
+		}
+	}
+
+	if blockedBy != "" {
+		return newResult(false, "forbidden_pattern_found:"+blockedBy, nil, MessageData{Rule: blockedBy})
+	}
+
+	for i := range required {
+		if ctx.Err() != nil {
+			return newResult(ruleSet.onTimeout != config.TimeoutReject, "keyword_scan_timeout", nil)
+		}
+		req := &required[i]
+		if !slices.ContainsFunc(req.patterns, func(p compiledKeywordRule) bool {
+			_, ok := p.match(inputFor(&p))
+			return ok
+		}) {
+			return newResult(false, fmt.Sprintf("required_pattern_missing:'%s'", req.description), nil, MessageData{Rule: req.description})
+		}
+	}
+
+	switch {
+	case shadowedBy != "":
+		return newResult(true, "shadow_pattern_found:"+shadowedBy, nil)
+	case flaggedBy != "":
+		return newResult(true, "flagged_pattern_found:"+flaggedBy, nil)
 	}
 
 	return newResult(true, "no_forbidden_patterns_found", nil)
 }
+
+// truncateUTF8 shortens s to at most n bytes without splitting a rune.
+// A non-positive n leaves s unchanged.
+func truncateUTF8(s string, n int) string {
+	if n <= 0 || len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
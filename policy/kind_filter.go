@@ -3,6 +3,7 @@ package policy
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/nbd-wtf/go-nostr"
 
@@ -13,11 +14,39 @@ const (
 	kindFilterName = "KindFilter"
 )
 
-type KindFilter struct {
+type kindRuleSet struct {
+	enabled         bool
 	allowed, denied map[int]struct{}
 }
 
+type KindFilter struct {
+	filterBase
+
+	rules atomic.Pointer[kindRuleSet]
+}
+
 func NewKindFilter(cfg *config.KindFilterConfig) (*KindFilter, error) {
+	filter := &KindFilter{}
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+	return filter, nil
+}
+
+// Reload atomically replaces the allow and deny lists and returns the config
+// warnings that the constructor logs. The filter holds no other state.
+func (f *KindFilter) Reload(cfg *config.KindFilterConfig) ([]string, error) {
+	rules, warnings := compileKindRules(cfg)
+	f.rules.Store(rules)
+	f.dryRun.Store(cfg.DryRun)
+	return warnings, nil
+}
+
+func compileKindRules(cfg *config.KindFilterConfig) (*kindRuleSet, []string) {
+	warnings := disabledWithRulesWarning(kindFilterName, cfg.Enabled, len(cfg.AllowedKinds)+len(cfg.DeniedKinds))
+
 	deniedMap := make(map[int]struct{}, len(cfg.DeniedKinds))
 	for _, kind := range cfg.DeniedKinds {
 		deniedMap[kind] = struct{}{}
@@ -31,23 +60,30 @@ func NewKindFilter(cfg *config.KindFilterConfig) (*KindFilter, error) {
 		}
 	}
 
-	filter := &KindFilter{
+	return &kindRuleSet{
+		enabled: cfg.Enabled,
 		allowed: allowedMap,
 		denied:  deniedMap,
-	}
-
-	return filter, nil
+	}, warnings
 }
 
 func (f *KindFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(kindFilterName)
+	newResult := f.resultFunc(kindFilterName, event, meta)
+	rules := f.rules.Load()
+
+	if !rules.enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
-	if _, isDenied := f.denied[event.Kind]; isDenied {
+	if _, isDenied := rules.denied[event.Kind]; isDenied {
 		return newResult(false, fmt.Sprintf("kind_%d_denied", event.Kind), nil)
 	}
 
-	if f.allowed != nil {
-		if _, isAllowed := f.allowed[event.Kind]; !isAllowed {
+	if rules.allowed != nil {
+		if _, isAllowed := rules.allowed[event.Kind]; !isAllowed {
 			return newResult(false, fmt.Sprintf("kind_%d_not_allowed", event.Kind), nil)
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -34,16 +35,28 @@ func init() {
 }
 
 type LanguageFilter struct {
-	cfg               *config.LanguageFilterConfig
-	detector          lingua.LanguageDetector
-	allowedLangs      map[lingua.Language]struct{}
-	allowedKinds      map[int]struct{}
-	approvedCache     *lru.LRU[string, struct{}]
-	thresholds        map[lingua.Language]map[lingua.Language]float64
-	defaultThresholds map[lingua.Language]float64
+	filterBase
+
+	cfg            *config.LanguageFilterConfig
+	detector       lingua.LanguageDetector
+	allowedLangs   map[lingua.Language]struct{}
+	allowedKinds   map[int]struct{}
+	approvedCache  *lru.LRU[string, struct{}]
+	thresholds     languageThresholds
+	kindThresholds map[int]languageThresholds
+	sampleKey      []byte
+}
+
+// languageThresholds holds compiled PrimaryAcceptThreshold rules: the
+// confidence a primary language needs when a similar language is detected,
+// and its "default" for any other detected language.
+type languageThresholds struct {
+	similar  map[lingua.Language]map[lingua.Language]float64
+	defaults map[lingua.Language]float64
 }
 
 func NewLanguageFilter(cfg *config.LanguageFilterConfig, detector lingua.LanguageDetector) (*LanguageFilter, error) {
+	warnDisabledWithRules(languageFilterName, cfg.Enabled, len(cfg.AllowedLanguages))
 	if !cfg.Enabled {
 		return &LanguageFilter{cfg: cfg}, nil
 	}
@@ -67,59 +80,78 @@ func NewLanguageFilter(cfg *config.LanguageFilterConfig, detector lingua.Languag
 		allowedKinds[k] = struct{}{}
 	}
 
-	thresholds := make(map[lingua.Language]map[lingua.Language]float64)
-	defaultThresholds := make(map[lingua.Language]float64)
-
-	for primaryStr, similarMap := range cfg.PrimaryAcceptThreshold {
-		primaryLang, ok := languageLookupMap[strings.ToLower(primaryStr)]
-		if !ok {
-			slog.Warn("LanguageFilter config warning: primary language in threshold rules not found, skipping rule", "language", primaryStr)
-			continue
-		}
-		thresholds[primaryLang] = make(map[lingua.Language]float64)
-		for similarStr, confidence := range similarMap {
-			if strings.ToLower(similarStr) == "default" {
-				defaultThresholds[primaryLang] = confidence
-			} else if similarLang, ok := languageLookupMap[strings.ToLower(similarStr)]; ok {
-				thresholds[primaryLang][similarLang] = confidence
-			} else {
-				slog.Warn("LanguageFilter config warning: unsupported similar language in threshold rule; ignored", "primary", primaryStr, "similar", similarStr)
-			}
+	// Per-kind thresholds replace the global ones for their kinds.
+	kindThresholds := make(map[int]languageThresholds)
+	for _, rule := range cfg.KindThresholds {
+		compiled := compileLanguageThresholds(rule.PrimaryAcceptThreshold)
+		for _, kind := range rule.Kinds {
+			kindThresholds[kind] = compiled
 		}
 	}
 
+	thresholds := compileLanguageThresholds(cfg.PrimaryAcceptThreshold)
+
+	// A typo in every allowed language would otherwise silently turn the
+	// filter into a passthrough.
+	if len(allowedMap) == 0 && len(thresholds.similar) == 0 && len(kindThresholds) == 0 && !cfg.AllowEmptyAsPassthrough {
+		return nil, errors.New("language filter enabled but no allowed language is supported; set allow_empty_as_passthrough to accept everything")
+	}
+
 	var cache *lru.LRU[string, struct{}]
 	if cfg.ApprovedCacheTTL > 0 && cfg.ApprovedCacheSize > 0 {
 		cache = lru.NewLRU[string, struct{}](cfg.ApprovedCacheSize, nil, cfg.ApprovedCacheTTL)
 	}
 
 	filter := &LanguageFilter{
-		cfg:               cfg,
-		detector:          detector,
-		allowedLangs:      allowedMap,
-		allowedKinds:      allowedKinds,
-		approvedCache:     cache,
-		thresholds:        thresholds,
-		defaultThresholds: defaultThresholds,
+		cfg:            cfg,
+		detector:       detector,
+		allowedLangs:   allowedMap,
+		allowedKinds:   allowedKinds,
+		approvedCache:  cache,
+		thresholds:     thresholds,
+		kindThresholds: kindThresholds,
+		sampleKey:      sampleKey(cfg.SampleSalt),
 	}
 
+	filter.dryRun.Store(cfg.DryRun)
 	return filter, nil
 }
 
 func (f *LanguageFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(languageFilterName)
+	newResult := f.resultFunc(languageFilterName, event, meta)
 
-	if !f.cfg.Enabled || len(f.allowedLangs) == 0 {
+	if !f.cfg.Enabled || (len(f.allowedLangs) == 0 && f.cfg.AllowEmptyAsPassthrough) {
 		return newResult(true, "filter_disabled", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 	if _, ok := f.allowedKinds[event.Kind]; !ok {
 		return newResult(true, "kind_not_checked", nil)
 	}
+	if sampledOut(event, f.cfg.SampleRate, f.sampleKey) {
+		if meta != nil {
+			meta["sampled_out"] = true
+		}
+		return newResult(true, "sampled_out", nil)
+	}
+	if f.cfg.TrustLanguageTags {
+		if lang, ok := taggedLanguage(event); ok {
+			langCode := lang.IsoCode639_1().String()
+			if _, isAllowed := f.allowedLangs[lang]; !isAllowed {
+				return newResult(false, fmt.Sprintf("language_not_allowed_by_tag:'%s'", langCode), nil)
+			}
+			if meta != nil {
+				meta["language"] = langCode
+			}
+			return newResult(true, fmt.Sprintf("language_allowed_by_tag:'%s'", langCode), nil)
+		}
+	}
 	if f.cfg.MinLengthForCheck > 0 && len(event.Content) < f.cfg.MinLengthForCheck {
 		return newResult(true, "content_too_short", nil)
 	}
 	if f.approvedCache != nil {
-		if _, ok := f.approvedCache.Get(event.PubKey); ok {
+		if _, ok := f.approvedCache.Get(f.cacheKey(event)); ok {
 			return newResult(true, "pubkey_in_cache", nil)
 		}
 	}
@@ -128,16 +160,31 @@ func (f *LanguageFilter) Match(_ context.Context, event *nostr.Event, meta map[s
 	if len(cleanedContent) < f.cfg.MinLengthForCheck {
 		return newResult(true, "cleaned_content_too_short", nil)
 	}
+	if f.cfg.MinWordsForCheck > 0 && len(strings.Fields(cleanedContent)) < f.cfg.MinWordsForCheck {
+		return newResult(true, "cleaned_content_too_few_words", nil)
+	}
 
 	detectedLang, detected := f.detector.DetectLanguageOf(cleanedContent)
 	if !detected {
-		return newResult(false, "language_undetectable", nil)
+		return f.undetected(newResult, cleanedContent, meta)
 	}
 
 	langCode := detectedLang.IsoCode639_1().String()
-	if _, isAllowed := f.allowedLangs[detectedLang]; isAllowed {
+	_, isAllowed := f.allowedLangs[detectedLang]
+
+	// A low-confidence match to an allowed language is treated like a
+	// disallowed one, so it must clear the threshold rules below.
+	lowConfidence := -1.0
+	if isAllowed && f.cfg.MinConfidenceForAllowed > 0 {
+		if confidence := f.detector.ComputeLanguageConfidence(cleanedContent, detectedLang); confidence < f.cfg.MinConfidenceForAllowed {
+			isAllowed = false
+			lowConfidence = confidence
+		}
+	}
+
+	if isAllowed {
 		if f.approvedCache != nil {
-			f.approvedCache.Add(event.PubKey, struct{}{})
+			f.approvedCache.Add(f.cacheKey(event), struct{}{})
 		}
 		if meta != nil {
 			meta["language"] = langCode
@@ -145,15 +192,19 @@ func (f *LanguageFilter) Match(_ context.Context, event *nostr.Event, meta map[s
 		return newResult(true, fmt.Sprintf("language_allowed:'%s'", langCode), nil)
 	}
 
-	for primaryLang, similarLangsMap := range f.thresholds {
+	thresholds, ok := f.kindThresholds[event.Kind]
+	if !ok {
+		thresholds = f.thresholds
+	}
+	for primaryLang, similarLangsMap := range thresholds.similar {
 		threshold, hasRule := similarLangsMap[detectedLang]
 		if !hasRule {
-			threshold, hasRule = f.defaultThresholds[primaryLang]
+			threshold, hasRule = thresholds.defaults[primaryLang]
 		}
 		if hasRule {
 			if confidence := f.detector.ComputeLanguageConfidence(cleanedContent, primaryLang); confidence > threshold {
 				if f.approvedCache != nil {
-					f.approvedCache.Add(event.PubKey, struct{}{})
+					f.approvedCache.Add(f.cacheKey(event), struct{}{})
 				}
 				if meta != nil {
 					meta["language"] = langCode
@@ -164,9 +215,106 @@ func (f *LanguageFilter) Match(_ context.Context, event *nostr.Event, meta map[s
 		}
 	}
 
+	if lowConfidence >= 0 {
+		reason := fmt.Sprintf("language_confidence_too_low:'%s',confidence_%.2f,min_%.2f", langCode, lowConfidence, f.cfg.MinConfidenceForAllowed)
+		return newResult(false, reason, nil)
+	}
 	return newResult(false, fmt.Sprintf("language_not_allowed:'%s'", langCode), nil)
 }
 
+func compileLanguageThresholds(rules map[string]map[string]float64) languageThresholds {
+	compiled := languageThresholds{
+		similar:  make(map[lingua.Language]map[lingua.Language]float64),
+		defaults: make(map[lingua.Language]float64),
+	}
+
+	for primaryStr, similarMap := range rules {
+		primaryLang, ok := languageLookupMap[strings.ToLower(primaryStr)]
+		if !ok {
+			slog.Warn("LanguageFilter config warning: primary language in threshold rules not found, skipping rule", "language", primaryStr)
+			continue
+		}
+		compiled.similar[primaryLang] = make(map[lingua.Language]float64)
+		for similarStr, confidence := range similarMap {
+			if strings.ToLower(similarStr) == "default" {
+				compiled.defaults[primaryLang] = confidence
+			} else if similarLang, ok := languageLookupMap[strings.ToLower(similarStr)]; ok {
+				compiled.similar[primaryLang][similarLang] = confidence
+			} else {
+				slog.Warn("LanguageFilter config warning: unsupported similar language in threshold rule; ignored", "primary", primaryStr, "similar", similarStr)
+			}
+		}
+	}
+	return compiled
+}
+
+// cacheKey scopes approval to the event's kind when CachePerKind is set, so
+// passing the check on one kind does not approve the author for others.
+func (f *LanguageFilter) cacheKey(event *nostr.Event) string {
+	if f.cfg.CachePerKind {
+		return event.PubKey + ":" + strconv.Itoa(event.Kind)
+	}
+	return event.PubKey
+}
+
+// undetected applies the configured OnUndetected action when the detector
+// cannot determine a language.
+func (f *LanguageFilter) undetected(newResult func(bool, string, error, ...MessageData) (FilterResult, error), cleanedContent string, meta map[string]any) (FilterResult, error) {
+	accept := false
+	switch f.cfg.OnUndetected {
+	case config.UndetectedAccept:
+		accept = true
+	case config.UndetectedAcceptBelowLength:
+		accept = len(cleanedContent) < f.cfg.UndetectedMaxLength
+	}
+
+	if !accept {
+		return newResult(false, "language_undetectable", nil)
+	}
+	if meta != nil {
+		meta["language"] = "undetermined"
+	}
+	return newResult(true, "language_undetectable_accepted", nil)
+}
+
+// iso6391Namespace is the NIP-32 label namespace for ISO 639-1 codes.
+const iso6391Namespace = "ISO-639-1"
+
+// taggedLanguage returns the language declared by the event's first
+// resolvable `language` tag. Failing that, it accepts a NIP-32 label
+// ["l", code, "ISO-639-1"] when the event also carries ["L", "ISO-639-1"].
+func taggedLanguage(event *nostr.Event) (lingua.Language, bool) {
+	var labeled lingua.Language
+	var hasLabel, hasNamespace bool
+	for _, tag := range event.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "language":
+			if lang, ok := languageLookupMap[strings.ToLower(strings.TrimSpace(tag[1]))]; ok {
+				return lang, true
+			}
+		case "L":
+			if tag[1] == iso6391Namespace {
+				hasNamespace = true
+			}
+		case "l":
+			if hasLabel || len(tag) < 3 || tag[2] != iso6391Namespace {
+				continue
+			}
+			code := strings.ToLower(strings.TrimSpace(tag[1]))
+			if lang, ok := languageLookupMap[code]; ok && strings.ToLower(lang.IsoCode639_1().String()) == code {
+				labeled, hasLabel = lang, true
+			}
+		}
+	}
+	if hasLabel && hasNamespace {
+		return labeled, true
+	}
+	return lingua.Unknown, false
+}
+
 func GetGlobalDetector() lingua.LanguageDetector {
 	globalDetectorOnce.Do(func() {
 		globalDetector = lingua.NewLanguageDetectorBuilder().
@@ -188,3 +336,9 @@ func buildLanguageLookupMap() {
 		languageLookupMap[strings.ToLower(lang.IsoCode639_3().String())] = lang
 	}
 }
+
+// Close releases the filter's caches.
+func (f *LanguageFilter) Close() error {
+	purgeCache(f.approvedCache)
+	return nil
+}
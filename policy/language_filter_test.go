@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/pemistahl/lingua-go"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+func TestNewLanguageFilterGarbageConfig(t *testing.T) {
+	detector := lingua.NewLanguageDetectorBuilder().
+		FromLanguages(lingua.English, lingua.French).
+		Build()
+
+	tests := []struct {
+		name        string
+		cfg         config.LanguageFilterConfig
+		wantErr     bool
+		wantAllowed bool
+		wantReason  string
+	}{
+		{
+			name: "enabled with only unsupported codes",
+			cfg: config.LanguageFilterConfig{
+				Enabled:          true,
+				AllowedLanguages: []string{"xx", "klingon"},
+				KindsToCheck:     []int{1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported codes with passthrough",
+			cfg: config.LanguageFilterConfig{
+				Enabled:                 true,
+				AllowedLanguages:        []string{"xx", "klingon"},
+				KindsToCheck:            []int{1},
+				AllowEmptyAsPassthrough: true,
+			},
+			wantAllowed: true,
+			wantReason:  "filter_disabled",
+		},
+		{
+			name: "unsupported codes with a valid threshold rule",
+			cfg: config.LanguageFilterConfig{
+				Enabled:          true,
+				AllowedLanguages: []string{"xx"},
+				KindsToCheck:     []int{1},
+				PrimaryAcceptThreshold: map[string]map[string]float64{
+					"en": {"default": 0.5},
+				},
+			},
+			wantAllowed: false,
+			wantReason:  "language_not_allowed:'FR'",
+		},
+		{
+			name: "disabled with unsupported codes",
+			cfg: config.LanguageFilterConfig{
+				AllowedLanguages: []string{"xx", "klingon"},
+			},
+			wantAllowed: true,
+			wantReason:  "filter_disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewLanguageFilter(&tt.cfg, detector)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			ev := &nostr.Event{Kind: 1, Content: "bonjour tout le monde, comment allez-vous"}
+			res, err := filter.Match(context.Background(), ev, map[string]any{})
+			if err != nil {
+				t.Fatalf("unexpected Match error: %v", err)
+			}
+			if res.Allowed != tt.wantAllowed || res.Reason != tt.wantReason {
+				t.Fatalf("got allowed=%v reason=%q, want allowed=%v reason=%q", res.Allowed, res.Reason, tt.wantAllowed, tt.wantReason)
+			}
+		})
+	}
+}
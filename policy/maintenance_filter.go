@@ -0,0 +1,68 @@
+package policy
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	maintenanceFilterName = "MaintenanceFilter"
+)
+
+type maintenanceState struct {
+	readOnly bool
+	reason   string
+}
+
+// MaintenanceFilter is a runtime kill switch: while read-only, it rejects
+// every event except those from exempt pubkeys.
+type MaintenanceFilter struct {
+	filterBase
+
+	cfg    *config.MaintenanceFilterConfig
+	exempt map[string]struct{}
+	state  atomic.Pointer[maintenanceState]
+}
+
+func NewMaintenanceFilter(cfg *config.MaintenanceFilterConfig) (*MaintenanceFilter, error) {
+	filter := &MaintenanceFilter{
+		cfg:    cfg,
+		exempt: pubkeySet(cfg.ExemptPubkeys),
+	}
+	filter.SetReadOnly(cfg.ReadOnly, cfg.Reason)
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// SetReadOnly switches maintenance mode on or off. The reason is appended to
+// rejection reasons while read-only.
+func (f *MaintenanceFilter) SetReadOnly(readOnly bool, reason string) {
+	f.state.Store(&maintenanceState{readOnly: readOnly, reason: reason})
+}
+
+func (f *MaintenanceFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(maintenanceFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+
+	state := f.state.Load()
+	if !state.readOnly {
+		return newResult(true, "relay_writable", nil)
+	}
+	if _, ok := f.exempt[event.PubKey]; ok {
+		return newResult(true, "pubkey_exempt", nil)
+	}
+
+	reason := "read_only_maintenance"
+	if state.reason != "" {
+		reason += ":" + state.reason
+	}
+	return newResult(false, reason, nil)
+}
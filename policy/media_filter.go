@@ -0,0 +1,126 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	mediaFilterName = "MediaFilter"
+)
+
+type MediaFilter struct {
+	filterBase
+
+	cfg          *config.MediaFilterConfig
+	kinds        map[int]struct{}
+	schemes      map[string]struct{}
+	mimePrefixes []string
+}
+
+func NewMediaFilter(cfg *config.MediaFilterConfig) (*MediaFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	allowedSchemes := cfg.AllowedSchemes
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = []string{"https", "http"}
+	}
+	schemes := make(map[string]struct{}, len(allowedSchemes))
+	for _, s := range allowedSchemes {
+		schemes[strings.ToLower(s)] = struct{}{}
+	}
+
+	mimePrefixes := make([]string, 0, len(cfg.AllowedMimePrefixes))
+	for _, p := range cfg.AllowedMimePrefixes {
+		// Accept both "image/" and "image/*" forms.
+		mimePrefixes = append(mimePrefixes, strings.ToLower(strings.TrimSuffix(p, "*")))
+	}
+
+	filter := &MediaFilter{
+		cfg:          cfg,
+		kinds:        kinds,
+		schemes:      schemes,
+		mimePrefixes: mimePrefixes,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *MediaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(mediaFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if _, ok := f.kinds[event.Kind]; !ok {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) == 0 || tag[0] != "imeta" {
+			continue
+		}
+		if reason := f.checkIMeta(tag[1:]); reason != "" {
+			return newResult(false, reason, nil)
+		}
+	}
+
+	return newResult(true, "media_ok", nil)
+}
+
+// checkIMeta validates the "key value" entries of a single NIP-92 imeta tag
+// and returns a rejection reason, or an empty string if the tag is valid.
+func (f *MediaFilter) checkIMeta(entries []string) string {
+	var rawURL, mime string
+	for _, entry := range entries {
+		key, value, _ := strings.Cut(entry, " ")
+		switch key {
+		case "url":
+			rawURL = strings.TrimSpace(value)
+		case "m":
+			mime = strings.ToLower(strings.TrimSpace(value))
+		}
+	}
+
+	if rawURL == "" {
+		return "imeta_invalid:url_missing"
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "imeta_invalid:url_malformed"
+	}
+	if _, ok := f.schemes[strings.ToLower(u.Scheme)]; !ok {
+		return fmt.Sprintf("imeta_invalid:url_scheme_'%s'_not_allowed", u.Scheme)
+	}
+
+	if mime == "" {
+		if f.cfg.RequireMimeType {
+			return "imeta_invalid:m_missing"
+		}
+		return ""
+	}
+	if len(f.mimePrefixes) > 0 && !hasAnyPrefix(mime, f.mimePrefixes) {
+		return fmt.Sprintf("imeta_invalid:m_'%s'_not_allowed", mime)
+	}
+
+	return ""
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package policy
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+// MergeKeywordConfigs composes a shared base config with a per-relay
+// override. Rules are appended, base first. Enabled and DryRun come from
+// override (or base, when override is nil); MaxScanBytes and OnTimeout come
+// from override when set, and override's messages replace base's for the
+// same reason code. Either argument may be nil, and neither is modified.
+func MergeKeywordConfigs(base, override *config.KeywordFilterConfig) *config.KeywordFilterConfig {
+	if base == nil {
+		base = &config.KeywordFilterConfig{}
+	}
+	if override == nil {
+		override = &config.KeywordFilterConfig{Enabled: base.Enabled, DryRun: base.DryRun}
+	}
+
+	merged := &config.KeywordFilterConfig{
+		Enabled:      override.Enabled,
+		DryRun:       override.DryRun,
+		MaxScanBytes: base.MaxScanBytes,
+		OnTimeout:    base.OnTimeout,
+		Messages:     mergeMessages(base.Messages, override.Messages),
+		Rules:        cloneRules(cloneKeywordRule, base.Rules, override.Rules),
+	}
+	if override.MaxScanBytes != 0 {
+		merged.MaxScanBytes = override.MaxScanBytes
+	}
+	if override.OnTimeout != "" {
+		merged.OnTimeout = override.OnTimeout
+	}
+	return merged
+}
+
+// MergeTagsConfigs composes a shared base config with a per-relay override.
+// Rules are appended, base first; since a kind uses the last rule listing
+// it, an override rule replaces base rules for its kinds. Enabled, DryRun
+// and NormalizeTagNames come from override, or base when override is nil.
+// Either argument may be nil, and neither is modified.
+func MergeTagsConfigs(base, override *config.TagsFilterConfig) *config.TagsFilterConfig {
+	if base == nil {
+		base = &config.TagsFilterConfig{}
+	}
+	if override == nil {
+		override = &config.TagsFilterConfig{
+			Enabled:           base.Enabled,
+			DryRun:            base.DryRun,
+			NormalizeTagNames: base.NormalizeTagNames,
+		}
+	}
+
+	merged := &config.TagsFilterConfig{
+		Enabled:           override.Enabled,
+		DryRun:            override.DryRun,
+		NormalizeTagNames: override.NormalizeTagNames,
+		Rules:             cloneRules(cloneTagRule, base.Rules, override.Rules),
+	}
+	return merged
+}
+
+// cloneRules concatenates rule lists, deep-copying each rule so that the
+// merged config shares no slices or maps with its inputs.
+func cloneRules[R any](clone func(R) R, lists ...[]R) []R {
+	var merged []R
+	for _, rules := range lists {
+		for _, rule := range rules {
+			merged = append(merged, clone(rule))
+		}
+	}
+	return merged
+}
+
+func cloneKeywordRule(rule config.KeywordRule) config.KeywordRule {
+	rule.Kinds = slices.Clone(rule.Kinds)
+	rule.Words = slices.Clone(rule.Words)
+	rule.Regexps = slices.Clone(rule.Regexps)
+	return rule
+}
+
+func cloneTagRule(rule config.TagRule) config.TagRule {
+	rule.Kinds = slices.Clone(rule.Kinds)
+	if rule.MaxTags != nil {
+		maxTags := *rule.MaxTags
+		rule.MaxTags = &maxTags
+	}
+	rule.RequiredTags = slices.Clone(rule.RequiredTags)
+	rule.MaxTagCounts = maps.Clone(rule.MaxTagCounts)
+	if rule.ConditionalRequirements != nil {
+		conds := make([]config.ConditionalReq, len(rule.ConditionalRequirements))
+		for i, cond := range rule.ConditionalRequirements {
+			conds[i] = config.ConditionalReq{IfTag: cond.IfTag, ThenRequire: slices.Clone(cond.ThenRequire)}
+		}
+		rule.ConditionalRequirements = conds
+	}
+	if rule.AllowedTagValues != nil {
+		values := make(map[string][]string, len(rule.AllowedTagValues))
+		for name, vals := range rule.AllowedTagValues {
+			values[name] = slices.Clone(vals)
+		}
+		rule.AllowedTagValues = values
+	}
+	rule.UniqueValueTags = slices.Clone(rule.UniqueValueTags)
+	return rule
+}
+
+func mergeMessages(base, override map[string]string) map[string]string {
+	if len(base) == 0 && len(override) == 0 {
+		return nil
+	}
+	merged := maps.Clone(base)
+	if merged == nil {
+		merged = make(map[string]string, len(override))
+	}
+	maps.Copy(merged, override)
+	return merged
+}
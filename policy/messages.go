@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// MessageData is the data available to rejection message templates.
+type MessageData struct {
+	// Kind is the event's kind.
+	Kind int
+	// Limit is the configured limit that was exceeded, if any.
+	Limit any
+	// Got is the observed value, if any.
+	Got any
+	// Rule names the rule or pattern that matched, if any.
+	Rule string
+}
+
+// messageTemplates maps reason codes to operator-provided templates.
+type messageTemplates map[string]*template.Template
+
+// compileMessages parses templates keyed by reason code. It returns nil when
+// there are none.
+func compileMessages(filterName string, messages map[string]string) (messageTemplates, error) {
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	compiled := make(messageTemplates, len(messages))
+	for code, text := range messages {
+		tmpl, err := template.New(filterName + "/" + code).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid message template for '%s': %w", code, err)
+		}
+		compiled[code] = tmpl
+	}
+	return compiled, nil
+}
+
+// render returns the message for reason, or "" when no template is
+// configured for its code or rendering fails.
+func (t messageTemplates) render(reason string, data MessageData) string {
+	code, _, _ := strings.Cut(reason, ":")
+	tmpl, ok := t[code]
+	if !ok {
+		return ""
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return ""
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	muteFilterName = "MuteFilter"
+)
+
+type MuteFilter struct {
+	filterBase
+
+	mu    sync.RWMutex
+	cfg   *config.MuteFilterConfig
+	muted map[string]struct{}
+}
+
+func NewMuteFilter(cfg *config.MuteFilterConfig) (*MuteFilter, error) {
+	warnDisabledWithRules(muteFilterName, cfg.Enabled, len(cfg.Pubkeys))
+
+	filter := &MuteFilter{cfg: cfg}
+	filter.Update(cfg.Pubkeys)
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// Update replaces the set of muted pubkeys.
+func (f *MuteFilter) Update(pubkeys []string) {
+	muted := make(map[string]struct{}, len(pubkeys))
+	for _, pk := range pubkeys {
+		muted[pk] = struct{}{}
+	}
+
+	f.mu.Lock()
+	f.muted = muted
+	f.mu.Unlock()
+}
+
+func (f *MuteFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(muteFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	// Update swaps the whole set, so the snapshot can be read unlocked.
+	f.mu.RLock()
+	muted := f.muted
+	f.mu.RUnlock()
+
+	if _, isMuted := muted[event.PubKey]; isMuted {
+		return newResult(false, "pubkey_muted", nil)
+	}
+
+	if f.cfg.BlockMentions {
+		for _, tag := range event.Tags {
+			if len(tag) < 2 || tag[0] != "p" {
+				continue
+			}
+			if _, isMuted := muted[tag[1]]; isMuted {
+				return newResult(false, fmt.Sprintf("mentions_muted_pubkey:'%s'", tag[1]), nil)
+			}
+		}
+	}
+
+	return newResult(true, "pubkey_not_muted", nil)
+}
@@ -0,0 +1,174 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip05"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	nip05FilterName = "NIP05Filter"
+)
+
+type nip05Verification struct {
+	identifier string
+	valid      bool
+	// expiresAt is set for failed lookups, which are cached for FailureTTL
+	// instead of the full CacheTTL.
+	expiresAt time.Time
+}
+
+type NIP05Filter struct {
+	filterBase
+
+	cfg        *config.NIP05FilterConfig
+	kinds      map[int]struct{}
+	verified   *lru.LRU[string, nip05Verification]
+	failureTTL time.Duration
+	timeout    time.Duration
+	sampleKey  []byte
+	fetch      func(ctx context.Context, identifier string) (nip05.WellKnownResponse, string, error)
+}
+
+func NewNIP05Filter(cfg *config.NIP05FilterConfig) (*NIP05Filter, error) {
+	if !cfg.Enabled {
+		return &NIP05Filter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.RequireForKinds))
+	for _, k := range cfg.RequireForKinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 10000
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	failureTTL := cfg.FailureTTL
+	if failureTTL <= 0 {
+		failureTTL = 5 * time.Minute
+	}
+	failureTTL = min(failureTTL, ttl)
+	timeout := cfg.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	filter := &NIP05Filter{
+		cfg:        cfg,
+		kinds:      kinds,
+		verified:   lru.NewLRU[string, nip05Verification](size, nil, ttl),
+		failureTTL: failureTTL,
+		timeout:    timeout,
+		sampleKey:  sampleKey(cfg.SampleSalt),
+		fetch:      nip05.Fetch,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *NIP05Filter) Match(ctx context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(nip05FilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+	if sampledOut(event, f.cfg.SampleRate, f.sampleKey) {
+		if meta != nil {
+			meta["sampled_out"] = true
+		}
+		return newResult(true, "sampled_out", nil)
+	}
+
+	identifier := nip05Identifier(event, meta)
+
+	now := f.now()
+	cached, ok := f.verified.Get(event.PubKey)
+	if ok && !cached.expiresAt.IsZero() && !now.Before(cached.expiresAt) {
+		ok = false
+	}
+	if !ok || (identifier != "" && cached.identifier != identifier) {
+		if identifier == "" {
+			return newResult(false, "nip05_missing", nil)
+		}
+		cached = nip05Verification{identifier: identifier}
+		valid, err := f.verify(ctx, identifier, event.PubKey)
+		if err != nil {
+			// An unreachable or broken well-known endpoint fails verification
+			// and is retried after FailureTTL rather than on every event.
+			cached.expiresAt = now.Add(f.failureTTL)
+		} else {
+			cached.valid = valid
+		}
+		f.verified.Add(event.PubKey, cached)
+	}
+
+	if !cached.valid {
+		return newResult(false, "nip05_verification_failed", nil)
+	}
+
+	if meta != nil {
+		meta["nip05_verified"] = cached.identifier
+	}
+	return newResult(true, "nip05_verified", nil)
+}
+
+// verify reports whether identifier resolves to pubkey. A malformed
+// identifier or a missing or different name is a definite failure; an error
+// means the well-known document could not be fetched or decoded, which is
+// cached for a shorter time.
+func (f *NIP05Filter) verify(ctx context.Context, identifier, pubkey string) (bool, error) {
+	if !nip05.IsValidIdentifier(identifier) {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	resp, name, err := f.fetch(ctx, identifier)
+	if err != nil {
+		return false, err
+	}
+	resolved, ok := resp.Names[name]
+	return ok && resolved == pubkey, nil
+}
+
+// nip05Identifier reads the identifier from kind-0 content, falling back to
+// meta["nip05"] for other kinds or when the profile does not declare one.
+func nip05Identifier(event *nostr.Event, meta map[string]any) string {
+	if event.Kind == nostr.KindProfileMetadata {
+		var profile struct {
+			NIP05 string `json:"nip05"`
+		}
+		if err := json.Unmarshal([]byte(event.Content), &profile); err == nil && profile.NIP05 != "" {
+			return profile.NIP05
+		}
+	}
+	identifier, _ := meta["nip05"].(string)
+	return identifier
+}
+
+// Close releases the filter's caches.
+func (f *NIP05Filter) Close() error {
+	purgeCache(f.verified)
+	return nil
+}
@@ -0,0 +1,81 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	nip10FilterName = "NIP10Filter"
+)
+
+// NIP10Filter checks that kind-1 events with several `e` tags use NIP-10
+// markers consistently. In strict mode unmarked (positional) `e` tags are
+// rejected as well.
+type NIP10Filter struct {
+	filterBase
+
+	cfg *config.NIP10FilterConfig
+}
+
+func NewNIP10Filter(cfg *config.NIP10FilterConfig) (*NIP10Filter, error) {
+	filter := &NIP10Filter{cfg: cfg}
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *NIP10Filter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(nip10FilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if event.Kind != nostr.KindTextNote {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	var eTags, roots, replies, unmarked int
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "e" {
+			continue
+		}
+		eTags++
+
+		var marker string
+		if len(tag) > 3 {
+			marker = tag[3]
+		}
+		switch marker {
+		case "root":
+			roots++
+		case "reply":
+			replies++
+		case "mention":
+		case "":
+			unmarked++
+		default:
+			return newResult(false, fmt.Sprintf("invalid_thread_markers:unknown_marker_'%s'", marker), nil)
+		}
+	}
+
+	if eTags < 2 {
+		return newResult(true, "thread_markers_ok", nil)
+	}
+
+	switch {
+	case roots > 1:
+		return newResult(false, fmt.Sprintf("invalid_thread_markers:multiple_roots_%d", roots), nil)
+	case replies > 1:
+		return newResult(false, fmt.Sprintf("invalid_thread_markers:multiple_replies_%d", replies), nil)
+	case replies > 0 && roots == 0:
+		return newResult(false, "invalid_thread_markers:reply_without_root", nil)
+	case f.cfg.Strict && unmarked > 0:
+		return newResult(false, "invalid_thread_markers:positional_e_tags", nil)
+	}
+
+	return newResult(true, "thread_markers_ok", nil)
+}
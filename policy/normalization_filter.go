@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	normalizationFilterName = "NormalizationFilter"
+)
+
+// NormalizationFilter rejects content carrying more than MaxInvisibleChars
+// zero-width or bidi-control characters. Zero-width joiners and non-joiners
+// are not counted. A MaxInvisibleChars of 0 rejects any invisible character;
+// a negative value disables the limit and only records the count in
+// meta["invisible_chars"].
+type NormalizationFilter struct {
+	filterBase
+
+	cfg   *config.NormalizationFilterConfig
+	kinds map[int]struct{}
+}
+
+func NewNormalizationFilter(cfg *config.NormalizationFilterConfig) (*NormalizationFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &NormalizationFilter{
+		cfg:   cfg,
+		kinds: kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *NormalizationFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(normalizationFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	_, count := normalizeContent(event.Content, NormalizeOptions{StripZeroWidth: true, StripBidi: true})
+	if meta != nil {
+		meta["invisible_chars"] = count
+	}
+
+	if f.cfg.MaxInvisibleChars >= 0 && count > f.cfg.MaxInvisibleChars {
+		reason := fmt.Sprintf("too_many_invisible_chars:count_%d,max_%d", count, f.cfg.MaxInvisibleChars)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "invisible_chars_ok", nil)
+}
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"github.com/nbd-wtf/go-nostr"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeOptions selects which classes of invisible characters Normalize
+// removes in addition to NFC normalization. The zero-width joiner and
+// non-joiner are legitimate in emoji sequences and in scripts such as Persian
+// and the Indic scripts, so StripZeroWidth leaves them alone and StripJoiners
+// removes them separately.
+type NormalizeOptions struct {
+	StripZeroWidth bool
+	StripJoiners   bool
+	StripBidi      bool
+}
+
+// Normalize applies NFC normalization to content and strips the invisible
+// characters selected by opts.
+func Normalize(content string, opts NormalizeOptions) string {
+	normalized, _ := normalizeContent(content, opts)
+	return normalized
+}
+
+func normalizeContent(content string, opts NormalizeOptions) (string, int) {
+	content = norm.NFC.String(content)
+	if !opts.StripZeroWidth && !opts.StripJoiners && !opts.StripBidi {
+		return content, 0
+	}
+
+	stripped := 0
+	var b strings.Builder
+	b.Grow(len(content))
+	for _, r := range content {
+		if (opts.StripZeroWidth && isZeroWidth(r)) || (opts.StripJoiners && isJoiner(r)) || (opts.StripBidi && isBidiControl(r)) {
+			stripped++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), stripped
+}
+
+func isZeroWidth(r rune) bool {
+	switch r {
+	case '\u00AD', '\u180E', '\u200B', '\u2060', '\uFEFF':
+		return true
+	}
+	return false
+}
+
+func isJoiner(r rune) bool {
+	return r == '\u200C' || r == '\u200D'
+}
+
+func isBidiControl(r rune) bool {
+	switch {
+	case r == '\u061C', r == '\u200E', r == '\u200F':
+		return true
+	case r >= '\u202A' && r <= '\u202E':
+		return true
+	case r >= '\u2066' && r <= '\u2069':
+		return true
+	}
+	return false
+}
+
+// normalizeForHash lowercases content, applies NFC, strips invisible
+// characters and collapses whitespace so trivial variations hash identically.
+func normalizeForHash(content string) string {
+	normalized := Normalize(content, NormalizeOptions{StripZeroWidth: true, StripJoiners: true, StripBidi: true})
+	return strings.Join(strings.Fields(strings.ToLower(normalized)), " ")
+}
+
+// NormalizedContentHash returns the hex SHA-256 of content after NFC,
+// lowercasing, stripping invisible characters and collapsing whitespace.
+// Filters that compare content use it so their hashes agree.
+func NormalizedContentHash(content string) string {
+	sum := sha256.Sum256([]byte(normalizeForHash(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// contentHash returns meta["content_hash"] when an earlier filter has set it,
+// and otherwise computes NormalizedContentHash and stores it there.
+func contentHash(event *nostr.Event, meta map[string]any) string {
+	if hash, ok := meta["content_hash"].(string); ok && hash != "" {
+		return hash
+	}
+	hash := NormalizedContentHash(event.Content)
+	if meta != nil {
+		meta["content_hash"] = hash
+	}
+	return hash
+}
+
+// foldDiacritics decomposes s (NFD) and drops combining marks, so "café"
+// becomes "cafe".
+func foldDiacritics(s string) string {
+	decomposed := norm.NFD.String(s)
+	var b strings.Builder
+	b.Grow(len(decomposed))
+	for _, r := range decomposed {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	pileOnFilterName = "PileOnFilter"
+)
+
+// PileOnFilter protects a target pubkey from being flooded: once more than
+// MaxEventsPerTarget events from any authors reference it in `p` tags within
+// Window, further events referencing it are rejected until the window ends.
+type PileOnFilter struct {
+	filterBase
+
+	mu      sync.Mutex
+	cfg     *config.PileOnFilterConfig
+	kinds   map[int]struct{}
+	targets *lru.LRU[string, *int]
+}
+
+func NewPileOnFilter(cfg *config.PileOnFilterConfig) (*PileOnFilter, error) {
+	if !cfg.Enabled {
+		return &PileOnFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	filter := &PileOnFilter{
+		cfg:     cfg,
+		kinds:   kinds,
+		targets: lru.NewLRU[string, *int](size, nil, window),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *PileOnFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(pileOnFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxEventsPerTarget <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	targets := make(map[string]struct{})
+	for _, tag := range event.Tags {
+		if len(tag) >= 2 && tag[0] == "p" && tag[1] != event.PubKey {
+			targets[tag[1]] = struct{}{}
+		}
+	}
+	if len(targets) == 0 {
+		return newResult(true, "no_targets", nil)
+	}
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(targets)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked checks every target and counts the event against them. The caller must hold f.mu.
+func (f *PileOnFilter) evaluateLocked(targets map[string]struct{}) (bool, string) {
+	// Check every target before counting, so a rejected event does not
+	// count against any of them.
+	for target := range targets {
+		if count, ok := f.targets.Get(target); ok && *count >= f.cfg.MaxEventsPerTarget {
+			return false, fmt.Sprintf("target_activity_exceeded:target:'%s'", target)
+		}
+	}
+	for target := range targets {
+		count, ok := f.targets.Get(target)
+		if !ok {
+			// The entry is added once so the window starts at the first event.
+			count = new(int)
+			f.targets.Add(target, count)
+		}
+		*count++
+	}
+
+	return true, "target_activity_ok"
+}
+
+// Close releases the filter's caches.
+func (f *PileOnFilter) Close() error {
+	purgeCache(f.targets)
+	return nil
+}
@@ -0,0 +1,42 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// Probe runs ev through f with a fresh meta and returns the decision and
+// reason, for sanity checks from an admin endpoint after loading a config.
+// A Match error is reported as a rejection carrying the result's reason.
+//
+// Probe calls Match, so stateful filters record the event as they would any
+// other: rate limiters spend tokens and activity trackers count it. Probe
+// with a pubkey reserved for the purpose to keep real users unaffected.
+// meta["probe"] is set so decision hooks can skip probe traffic.
+//
+// Probe is a function rather than a method promoted from filterBase, which
+// has no way to call the embedding filter's Match.
+func Probe(ctx context.Context, f Filter, ev *nostr.Event) (bool, string) {
+	res, err := f.Match(ctx, ev, map[string]any{"probe": true})
+	if err != nil {
+		return false, res.Reason
+	}
+	return res.Allowed, res.Reason
+}
+
+// ProbeAll runs ev through filters in order, sharing one fresh meta as a
+// chain would (the kit has no registry type to hang it on), and returns the result of the first filter that rejects it.
+// The bool is false when every filter accepts. The side effects documented
+// on Probe apply to each filter reached.
+func ProbeAll(ctx context.Context, ev *nostr.Event, filters ...Filter) (FilterResult, bool) {
+	meta := map[string]any{"probe": true}
+	for _, f := range filters {
+		res, err := f.Match(ctx, ev, meta)
+		if err != nil || !res.Allowed {
+			res.Allowed = false
+			return res, true
+		}
+	}
+	return FilterResult{}, false
+}
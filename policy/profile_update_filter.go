@@ -0,0 +1,103 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	profileUpdateFilterName = "ProfileUpdateFilter"
+)
+
+type profileState struct {
+	hash      string
+	updatedAt time.Time
+}
+
+// ProfileUpdateFilter rejects kind-0 updates that repeat the author's last
+// accepted metadata and, optionally, distinct updates arriving within
+// MinInterval of the previous one.
+type ProfileUpdateFilter struct {
+	filterBase
+
+	mu       sync.Mutex
+	cfg      *config.ProfileUpdateFilterConfig
+	profiles *lru.LRU[string, profileState]
+}
+
+func NewProfileUpdateFilter(cfg *config.ProfileUpdateFilterConfig) (*ProfileUpdateFilter, error) {
+	if !cfg.Enabled {
+		return &ProfileUpdateFilter{cfg: cfg}, nil
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+
+	filter := &ProfileUpdateFilter{
+		cfg:      cfg,
+		profiles: lru.NewLRU[string, profileState](size, nil, ttl),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ProfileUpdateFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(profileUpdateFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if event.Kind != nostr.KindProfileMetadata {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	hash := contentHash(event, meta)
+	now := f.now()
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(event, hash, now)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked compares the update with the author's last one and records it. The caller must hold f.mu.
+func (f *ProfileUpdateFilter) evaluateLocked(event *nostr.Event, hash string, now time.Time) (bool, string) {
+	if last, ok := f.profiles.Get(event.PubKey); ok {
+		if last.hash == hash {
+			return false, "duplicate_profile_update"
+		}
+		if f.cfg.MinInterval > 0 {
+			if elapsed := now.Sub(last.updatedAt); elapsed < f.cfg.MinInterval {
+				reason := fmt.Sprintf("profile_update_too_frequent:interval_%s,min_%s", elapsed.Round(time.Second), f.cfg.MinInterval)
+				return false, reason
+			}
+		}
+	}
+	f.profiles.Add(event.PubKey, profileState{hash: hash, updatedAt: now})
+
+	return true, "profile_update_ok"
+}
+
+// Close releases the filter's caches.
+func (f *ProfileUpdateFilter) Close() error {
+	purgeCache(f.profiles)
+	return nil
+}
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	protectedEventFilterName = "ProtectedEventFilter"
+)
+
+// ProtectedEventFilter enforces NIP-70: events carrying a `-` tag are only
+// accepted from a connection authenticated (NIP-42) as the event's author,
+// as reported in meta["authed_pubkey"].
+type ProtectedEventFilter struct {
+	filterBase
+
+	cfg *config.ProtectedEventFilterConfig
+}
+
+func NewProtectedEventFilter(cfg *config.ProtectedEventFilterConfig) (*ProtectedEventFilter, error) {
+	filter := &ProtectedEventFilter{cfg: cfg}
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ProtectedEventFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(protectedEventFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if !isProtected(event) {
+		return newResult(true, "event_not_protected", nil)
+	}
+
+	authed, _ := meta["authed_pubkey"].(string)
+	if authed == "" {
+		return newResult(false, "protected_event_auth_required", nil)
+	}
+	if authed != event.PubKey {
+		return newResult(false, "protected_event_auth_mismatch", nil)
+	}
+
+	return newResult(true, "protected_event_authed", nil)
+}
+
+func isProtected(event *nostr.Event) bool {
+	for _, tag := range event.Tags {
+		if len(tag) > 0 && tag[0] == "-" {
+			return true
+		}
+	}
+	return false
+}
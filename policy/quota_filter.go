@@ -0,0 +1,159 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	quotaFilterName = "QuotaFilter"
+)
+
+type quotaCounter struct {
+	count   int
+	resetAt time.Time
+}
+
+type processedQuotaRule struct {
+	rule *config.QuotaRule
+	id   string
+}
+
+type QuotaFilter struct {
+	filterBase
+
+	mu         sync.Mutex
+	cfg        *config.QuotaFilterConfig
+	period     time.Duration
+	counters   *lru.LRU[string, *quotaCounter]
+	kindToRule map[int]processedQuotaRule
+}
+
+func NewQuotaFilter(cfg *config.QuotaFilterConfig) (*QuotaFilter, error) {
+	warnDisabledWithRules(quotaFilterName, cfg.Enabled, len(cfg.Rules))
+	if !cfg.Enabled {
+		return &QuotaFilter{cfg: cfg}, nil
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	period := cfg.Period
+	if period <= 0 {
+		period = 24 * time.Hour
+	}
+
+	kindMap := make(map[int]processedQuotaRule, len(cfg.Rules))
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		processed := processedQuotaRule{
+			rule: rule,
+			id:   "rule-" + strconv.Itoa(i),
+		}
+		for _, kind := range rule.Kinds {
+			kindMap[kind] = processed
+		}
+	}
+
+	filter := &QuotaFilter{
+		cfg:        cfg,
+		period:     period,
+		counters:   lru.NewLRU[string, *quotaCounter](size, nil, period),
+		kindToRule: kindMap,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *QuotaFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(quotaFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	limit := f.cfg.MaxEventsPerPeriod
+	ruleID := "default"
+	if processed, ok := f.kindToRule[event.Kind]; ok {
+		limit = processed.rule.MaxEvents
+		ruleID = processed.id
+	}
+	if limit <= 0 {
+		return newResult(true, "quota_unlimited_for_kind", nil)
+	}
+
+	userKeys := make([]string, 0, 2)
+	remoteIP, _ := meta["remote_ip"].(string)
+
+	switch f.cfg.By {
+	case config.RateByIP:
+		if remoteIP != "" {
+			userKeys = append(userKeys, "ip:"+remoteIP)
+		}
+	case config.RateByPubKey:
+		if event.PubKey != "" {
+			userKeys = append(userKeys, "pk:"+event.PubKey)
+		}
+	case config.RateByBoth:
+		if remoteIP != "" {
+			userKeys = append(userKeys, "ip:"+remoteIP)
+		}
+		if event.PubKey != "" {
+			userKeys = append(userKeys, "pk:"+event.PubKey)
+		}
+	}
+
+	now := f.now()
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(ruleID, userKeys, limit, now)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked checks every key's quota and charges them. The caller must hold f.mu.
+func (f *QuotaFilter) evaluateLocked(ruleID string, userKeys []string, limit int, now time.Time) (bool, string) {
+	counters := make([]*quotaCounter, 0, len(userKeys))
+	for _, userKey := range userKeys {
+		key := ruleID + ":" + userKey
+		counter, ok := f.counters.Get(key)
+		if !ok || !now.Before(counter.resetAt) {
+			// The entry is added once per period so that its TTL tracks the
+			// period start rather than the latest event.
+			counter = &quotaCounter{resetAt: now.Add(f.period)}
+			f.counters.Add(key, counter)
+		}
+		if counter.count >= limit {
+			reason := fmt.Sprintf("quota_exceeded:max_%d,resets_in_%s", limit, counter.resetAt.Sub(now).Round(time.Second))
+			return false, reason
+		}
+		counters = append(counters, counter)
+	}
+
+	// Only charge the quota once every key has room for the event.
+	for _, counter := range counters {
+		counter.count++
+	}
+
+	return true, "quota_ok"
+}
+
+// Close releases the filter's caches.
+func (f *QuotaFilter) Close() error {
+	purgeCache(f.counters)
+	return nil
+}
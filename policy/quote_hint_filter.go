@@ -0,0 +1,57 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	quoteHintFilterName = "QuoteHintFilter"
+)
+
+// QuoteHintFilter requires NIP-18 quote reposts (kind 1 with `q` tags) to
+// carry a ws:// or wss:// relay hint in every `q` tag, so outbox clients
+// can resolve the quoted event.
+type QuoteHintFilter struct {
+	filterBase
+
+	cfg *config.QuoteHintFilterConfig
+}
+
+func NewQuoteHintFilter(cfg *config.QuoteHintFilterConfig) (*QuoteHintFilter, error) {
+	filter := &QuoteHintFilter{cfg: cfg}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *QuoteHintFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(quoteHintFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if event.Kind != nostr.KindTextNote || !hasTag(event, "q") {
+		return newResult(true, "not_a_quote", nil)
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "q" {
+			continue
+		}
+		if len(tag) < 3 || tag[2] == "" {
+			return newResult(false, fmt.Sprintf("missing_quote_relay_hint:'%s'", tag[1]), nil)
+		}
+		if !isRelayURL(tag[2]) {
+			return newResult(false, fmt.Sprintf("invalid_quote_relay_hint:'%s'", tag[2]), nil)
+		}
+	}
+	return newResult(true, "quote_hints_ok", nil)
+}
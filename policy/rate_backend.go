@@ -0,0 +1,169 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"golang.org/x/time/rate"
+)
+
+// RateBackend stores token buckets for RateLimiterFilter. Allow reports
+// whether cost tokens could be taken from the bucket identified by key,
+// creating the bucket with rate r and burst b if it does not exist.
+type RateBackend interface {
+	Allow(ctx context.Context, key string, r float64, b int, cost int) (bool, error)
+}
+
+// RateTokenReporter is optionally implemented by a RateBackend that can
+// report the tokens remaining in a bucket.
+type RateTokenReporter interface {
+	Tokens(ctx context.Context, key string) (float64, bool)
+}
+
+// MemoryRateBackend keeps token buckets in a process-local expirable LRU.
+// It is the default backend.
+type MemoryRateBackend struct {
+	limiters *lru.LRU[string, *rate.Limiter]
+	clock    atomic.Pointer[Clock]
+}
+
+func NewMemoryRateBackend(size int, ttl time.Duration) *MemoryRateBackend {
+	return &MemoryRateBackend{
+		limiters: lru.NewLRU[string, *rate.Limiter](size, nil, ttl),
+	}
+}
+
+// SetClock replaces the time source used to refill buckets. Passing nil
+// restores SystemClock.
+func (b *MemoryRateBackend) SetClock(clock Clock) {
+	if clock == nil {
+		b.clock.Store(nil)
+		return
+	}
+	b.clock.Store(&clock)
+}
+
+func (b *MemoryRateBackend) now() time.Time {
+	if clock := b.clock.Load(); clock != nil {
+		return (*clock).Now()
+	}
+	return SystemClock.Now()
+}
+
+func (b *MemoryRateBackend) Allow(_ context.Context, key string, r float64, burst int, cost int) (bool, error) {
+	return b.getLimiter(key, r, burst).AllowN(b.now(), cost), nil
+}
+
+// Tokens returns the tokens currently available in the bucket for key.
+func (b *MemoryRateBackend) Tokens(_ context.Context, key string) (float64, bool) {
+	limiter, ok := b.limiters.Peek(key)
+	if !ok {
+		return 0, false
+	}
+	return limiter.TokensAt(b.now()), true
+}
+
+// Close releases all buckets.
+func (b *MemoryRateBackend) Close() error {
+	purgeCache(b.limiters)
+	return nil
+}
+
+func (b *MemoryRateBackend) getLimiter(key string, r float64, burst int) *rate.Limiter {
+	if limiter, ok := b.limiters.Get(key); ok {
+		// Rules may have changed since the limiter was created.
+		if limiter.Limit() != rate.Limit(r) {
+			limiter.SetLimit(rate.Limit(r))
+		}
+		if limiter.Burst() != burst {
+			limiter.SetBurst(burst)
+		}
+		return limiter
+	}
+	limiter := rate.NewLimiter(rate.Limit(r), burst)
+	b.limiters.Add(key, limiter)
+	return limiter
+}
+
+// RedisEvalFunc runs a Lua script on a Redis server. With go-redis it is
+// typically implemented as:
+//
+//	func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisEvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// redisTokenBucketScript implements a token bucket stored as a Redis hash.
+// ARGV: rate (tokens/s), burst, cost, ttl (ms). Returns 1 if allowed. The
+// script reads the time from the Redis server so that every relay instance
+// refills buckets against the same clock, whatever their own clocks say.
+const redisTokenBucketScript = `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local cost = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+local elapsed = math.max(0, now - ts) / 1000
+tokens = math.min(burst, tokens + elapsed * rate)
+
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return allowed
+`
+
+// RedisRateBackend keeps token buckets in Redis so that limits are shared
+// by every relay instance using the same server.
+type RedisRateBackend struct {
+	eval   RedisEvalFunc
+	prefix string
+	ttl    time.Duration
+}
+
+func NewRedisRateBackend(eval RedisEvalFunc, prefix string, ttl time.Duration) *RedisRateBackend {
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	return &RedisRateBackend{eval: eval, prefix: prefix, ttl: ttl}
+}
+
+func (b *RedisRateBackend) Allow(ctx context.Context, key string, r float64, burst int, cost int) (bool, error) {
+	res, err := b.eval(ctx, redisTokenBucketScript, []string{b.prefix + key},
+		strconv.FormatFloat(r, 'f', -1, 64), burst, cost, b.ttl.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("redis rate backend: %w", err)
+	}
+
+	switch v := res.(type) {
+	case int64:
+		return v == 1, nil
+	case int:
+		return v == 1, nil
+	default:
+		return false, fmt.Errorf("redis rate backend: unexpected script result %T", res)
+	}
+}
@@ -2,13 +2,16 @@ package policy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	lru "github.com/hashicorp/golang-lru/v2/expirable"
 	"github.com/nbd-wtf/go-nostr"
-	"golang.org/x/time/rate"
 
 	"github.com/lessucettes/adresu-kit/config"
 )
@@ -18,16 +21,66 @@ const (
 )
 
 type processedRateRule struct {
-	rule *config.RateLimitRule
-	id   string
+	rule   *config.RateLimitRule
+	id     string
+	label  string
+	exempt map[string]struct{}
 }
 
-type RateLimiterFilter struct {
+type rateRuleSet struct {
 	cfg        *config.RateLimiterConfig
-	limiters   *lru.LRU[string, *rate.Limiter]
 	kindToRule map[int]processedRateRule
+	exempt     map[string]struct{}
+}
+
+type RateLimiterFilter struct {
+	filterBase
+
+	rules   atomic.Pointer[rateRuleSet]
+	backend RateBackend
+	traffic trafficMeter
+}
+
+// trafficMeter estimates the relay-wide accept rate in one-minute windows.
+// The window rolls over lazily on the first observation after it ends.
+type trafficMeter struct {
+	count       atomic.Int64
+	windowStart atomic.Int64
+	lastRate    atomic.Uint64
+	measured    atomic.Bool
+}
+
+const trafficWindow = time.Minute
+
+// reset starts a fresh window at now and forgets the last measured rate.
+func (m *trafficMeter) reset(now time.Time) {
+	m.measured.Store(false)
+	m.count.Store(0)
+	m.windowStart.Store(now.UnixNano())
+}
+
+// rate returns the events-per-second rate of the last completed window. It
+// reports false until a full window has elapsed since the meter was reset.
+func (m *trafficMeter) rate(now time.Time) (float64, bool) {
+	start := m.windowStart.Load()
+	if elapsed := now.UnixNano() - start; elapsed >= int64(trafficWindow) {
+		if m.windowStart.CompareAndSwap(start, now.UnixNano()) {
+			perSecond := float64(m.count.Swap(0)) / time.Duration(elapsed).Seconds()
+			m.lastRate.Store(math.Float64bits(perSecond))
+			m.measured.Store(true)
+		}
+	}
+	if !m.measured.Load() {
+		return 0, false
+	}
+	return math.Float64frombits(m.lastRate.Load()), true
+}
+
+func (m *trafficMeter) add() {
+	m.count.Add(1)
 }
 
+// NewRateLimiterFilter creates a filter backed by an in-memory LRU of limiters.
 func NewRateLimiterFilter(cfg *config.RateLimiterConfig) (*RateLimiterFilter, error) {
 	size := cfg.CacheSize
 	if size <= 0 {
@@ -39,61 +92,133 @@ func NewRateLimiterFilter(cfg *config.RateLimiterConfig) (*RateLimiterFilter, er
 		ttl = time.Minute * 10
 	}
 
-	cache := lru.NewLRU[string, *rate.Limiter](size, nil, ttl)
+	return NewRateLimiterFilterWithBackend(cfg, NewMemoryRateBackend(size, ttl))
+}
+
+// NewRateLimiterFilterWithBackend creates a filter that stores its token
+// buckets in backend, e.g. a RedisRateBackend shared across relay instances.
+func NewRateLimiterFilterWithBackend(cfg *config.RateLimiterConfig, backend RateBackend) (*RateLimiterFilter, error) {
+	if backend == nil {
+		return nil, errors.New("rate limiter backend is nil")
+	}
+
+	filter := &RateLimiterFilter{backend: backend}
+	filter.traffic.reset(filter.now())
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+
+	return filter, nil
+}
+
+// Reload atomically replaces the rules and message templates and returns the
+// config warnings that the constructor logs. On error the previous
+// configuration stays active. Cache size and TTL are fixed at construction.
+// Existing buckets in the backend are preserved with their remaining tokens
+// and pick up new rates and bursts on their next use.
+func (f *RateLimiterFilter) Reload(cfg *config.RateLimiterConfig) ([]string, error) {
+	rules, warnings, err := compileRateRules(cfg)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := compileMessages(rateLimiterFilterName, cfg.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	f.rules.Store(rules)
+	f.setMessages(messages)
+	f.dryRun.Store(cfg.DryRun)
+	return warnings, nil
+}
+
+func compileRateRules(cfg *config.RateLimiterConfig) (*rateRuleSet, []string, error) {
+	if cfg.By == config.RateByTagValue && cfg.TagName == "" {
+		return nil, nil, errors.New("rate limiter keyed by tag_value requires tag_name")
+	}
+	warnings := disabledWithRulesWarning(rateLimiterFilterName, cfg.Enabled, len(cfg.Rules))
+
 	kindMap := make(map[int]processedRateRule, len(cfg.Rules))
 
 	for i := range cfg.Rules {
 		rule := &cfg.Rules[i]
 		processed := processedRateRule{
-			rule: rule,
-			id:   "rule-" + strconv.Itoa(i),
+			rule:   rule,
+			id:     "rule-" + strconv.Itoa(i),
+			label:  ruleLabel(rule.Description, i),
+			exempt: pubkeySet(rule.ExemptPubkeys),
 		}
 		for _, kind := range rule.Kinds {
 			kindMap[kind] = processed
 		}
 	}
 
-	filter := &RateLimiterFilter{
+	return &rateRuleSet{
 		cfg:        cfg,
-		limiters:   cache,
 		kindToRule: kindMap,
-	}
-
-	return filter, nil
+		exempt:     pubkeySet(cfg.ExemptPubkeys),
+	}, warnings, nil
 }
 
-func (f *RateLimiterFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(rateLimiterFilterName)
+func (f *RateLimiterFilter) Match(ctx context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(rateLimiterFilterName, event, meta)
+	rules := f.rules.Load()
+	cfg := rules.cfg
 
-	if !f.cfg.Enabled {
+	if !cfg.Enabled {
 		return newResult(true, "filter_disabled", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if _, ok := rules.exempt[event.PubKey]; ok {
+		return newResult(true, "pubkey_exempt", nil)
+	}
 
 	var currentRate float64
 	var currentBurst int
+	currentCost := 1
 	var ruleID string
 	var ruleDescription string
+	var targetRule *config.RateLimitRule
 
-	if processed, exists := f.kindToRule[event.Kind]; exists {
+	if processed, exists := rules.kindToRule[event.Kind]; exists {
+		setMatchedRule(meta, processed.label)
+		if _, ok := processed.exempt[event.PubKey]; ok {
+			return newResult(true, "pubkey_exempt_for_rule", nil)
+		}
 		currentRate = processed.rule.Rate
 		currentBurst = processed.rule.Burst
+		currentCost = max(processed.rule.Cost, 1)
 		ruleID = processed.id
 		ruleDescription = processed.rule.Description
+		if processed.rule.PerTarget {
+			targetRule = processed.rule
+		}
 	} else {
-		currentRate = f.cfg.DefaultRate
-		currentBurst = f.cfg.DefaultBurst
+		currentRate = cfg.DefaultRate
+		currentBurst = cfg.DefaultBurst
 		ruleID = "default"
 		ruleDescription = "default"
+		setMatchedRule(meta, "default")
 	}
 
 	if currentRate <= 0 {
 		return newResult(true, "rate_unlimited_for_kind", nil)
 	}
+	if cfg.QuietMultiplier > 1 {
+		// Rates are not scaled until a full window of traffic has been seen.
+		if rate, ok := f.traffic.rate(f.now()); ok && rate < cfg.QuietThreshold {
+			currentRate *= cfg.QuietMultiplier
+		}
+	}
 
 	userKeys := make([]string, 0, 2)
 	remoteIP, _ := meta["remote_ip"].(string)
 
-	switch f.cfg.By {
+	switch cfg.By {
 	case config.RateByIP:
 		if remoteIP != "" {
 			userKeys = append(userKeys, "ip:"+remoteIP)
@@ -109,24 +234,128 @@ func (f *RateLimiterFilter) Match(_ context.Context, event *nostr.Event, meta ma
 		if event.PubKey != "" {
 			userKeys = append(userKeys, "pk:"+event.PubKey)
 		}
+	case config.RateByTagValue:
+		// Each distinct value is its own bucket shared by all authors;
+		// events without the tag are not limited.
+		userKeys = tagValueKeys(event, cfg.TagName)
 	}
 
+	reporter, canReport := f.backend.(RateTokenReporter)
+	remaining := -1.0
+
 	for _, userKey := range userKeys {
 		cacheKey := fmt.Sprintf("%s:%s", ruleID, userKey)
-		limiter := f.getLimiter(cacheKey, currentRate, currentBurst)
-		if !limiter.Allow() {
-			reason := fmt.Sprintf("rate_limit_exceeded:rule:'%s'", ruleDescription)
-			return newResult(false, reason, nil)
+		allowed, err := f.backend.Allow(ctx, cacheKey, currentRate, currentBurst, currentCost)
+		if err != nil {
+			if cfg.FailOpen {
+				return newResult(true, "rate_limit_backend_failed_open", nil)
+			}
+			return newResult(false, "internal_rate_backend_failed", err)
+		}
+		if !allowed {
+			if tagValue, ok := strings.CutPrefix(userKey, "tag:"); ok {
+				reason := fmt.Sprintf("rate_limit_exceeded:tag:'%s',rule:'%s'", strings.Replace(tagValue, ":", "=", 1), ruleDescription)
+				return newResult(false, reason, nil, MessageData{Limit: currentBurst, Got: currentCost, Rule: ruleDescription})
+			}
+			reason := fmt.Sprintf("rate_limit_exceeded:rule:'%s',cost_%d", ruleDescription, currentCost)
+			return newResult(false, reason, nil, MessageData{Limit: currentBurst, Got: currentCost, Rule: ruleDescription})
+		}
+		if canReport {
+			if tokens, ok := reporter.Tokens(ctx, cacheKey); ok && (remaining < 0 || tokens < remaining) {
+				remaining = tokens
+			}
+		}
+	}
+
+	// Per-target rules additionally limit how often one author may hit the
+	// same target pubkey, e.g. reactions aimed at a single account.
+	if targetRule != nil && event.PubKey != "" {
+		if target := reactionTarget(event); target != "" {
+			targetRate, targetBurst := targetRule.TargetRate, targetRule.TargetBurst
+			if targetRate <= 0 {
+				targetRate, targetBurst = currentRate, currentBurst
+			}
+			cacheKey := fmt.Sprintf("%s:pk:%s:target:%s", ruleID, event.PubKey, target)
+			allowed, err := f.backend.Allow(ctx, cacheKey, targetRate, targetBurst, currentCost)
+			if err != nil {
+				if cfg.FailOpen {
+					return newResult(true, "rate_limit_backend_failed_open", nil)
+				}
+				return newResult(false, "internal_rate_backend_failed", err)
+			}
+			if !allowed {
+				reason := fmt.Sprintf("rate_limit_target_exceeded:rule:'%s',target:'%s'", ruleDescription, target)
+				return newResult(false, reason, nil, MessageData{Limit: targetBurst, Got: currentCost, Rule: ruleDescription})
+			}
 		}
 	}
+
+	// Report the tightest bucket so relays can warn users nearing a limit.
+	if meta != nil && remaining >= 0 {
+		meta["rate_tokens_remaining"] = remaining
+	}
+	f.traffic.add()
 	return newResult(true, "rate_limit_ok", nil)
 }
 
-func (f *RateLimiterFilter) getLimiter(key string, r float64, b int) *rate.Limiter {
-	if limiter, ok := f.limiters.Get(key); ok {
-		return limiter
+// reactionTarget returns the value of the last `p` tag, which NIP-25 reserves
+// for the author of the reacted-to event.
+func reactionTarget(event *nostr.Event) string {
+	for i := len(event.Tags) - 1; i >= 0; i-- {
+		if tag := event.Tags[i]; len(tag) >= 2 && tag[0] == "p" {
+			return tag[1]
+		}
+	}
+	return ""
+}
+
+// tagValueKeys returns a "tag:<name>:<value>" limiter key for each distinct
+// value of the event's name tags. Hashtag values are lowercased.
+func tagValueKeys(event *nostr.Event, name string) []string {
+	var keys []string
+	seen := make(map[string]struct{})
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != name || tag[1] == "" {
+			continue
+		}
+		value := tag[1]
+		if name == "t" {
+			value = strings.ToLower(value)
+		}
+		if _, dup := seen[value]; dup {
+			continue
+		}
+		seen[value] = struct{}{}
+		keys = append(keys, "tag:"+name+":"+value)
+	}
+	return keys
+}
+
+func pubkeySet(pubkeys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(pubkeys))
+	for _, pk := range pubkeys {
+		set[pk] = struct{}{}
+	}
+	return set
+}
+
+// SetClock replaces the filter's time source and passes it on to the
+// backend if the backend has a SetClock method, as MemoryRateBackend does.
+// The traffic meter restarts its window on the new clock.
+func (f *RateLimiterFilter) SetClock(clock Clock) {
+	f.filterBase.SetClock(clock)
+	f.traffic.reset(f.now())
+	if setter, ok := f.backend.(interface{ SetClock(Clock) }); ok {
+		setter.SetClock(clock)
+	}
+}
+
+// Close closes the backend if it implements io.Closer. A backend passed to
+// NewRateLimiterFilterWithBackend is closed too, so it must not be shared
+// with filters that outlive this one.
+func (f *RateLimiterFilter) Close() error {
+	if closer, ok := f.backend.(io.Closer); ok {
+		return closer.Close()
 	}
-	limiter := rate.NewLimiter(rate.Limit(r), b)
-	f.limiters.Add(key, limiter)
-	return limiter
+	return nil
 }
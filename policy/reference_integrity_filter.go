@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	referenceIntegrityFilterName = "ReferenceIntegrityFilter"
+)
+
+var defaultReferenceTagNames = []string{"e", "p"}
+
+type ReferenceIntegrityFilter struct {
+	filterBase
+
+	cfg      *config.ReferenceIntegrityFilterConfig
+	tagNames map[string]struct{}
+}
+
+func NewReferenceIntegrityFilter(cfg *config.ReferenceIntegrityFilterConfig) (*ReferenceIntegrityFilter, error) {
+	if !cfg.Enabled {
+		return &ReferenceIntegrityFilter{cfg: cfg}, nil
+	}
+
+	names := cfg.TagNames
+	if len(names) == 0 {
+		names = defaultReferenceTagNames
+	}
+	tagNames := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		tagNames[name] = struct{}{}
+	}
+
+	filter := &ReferenceIntegrityFilter{
+		cfg:      cfg,
+		tagNames: tagNames,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// Match requires `p` and `P` tag values to be valid public keys and values of
+// every other configured tag name to be 32-byte lowercase hex ids.
+func (f *ReferenceIntegrityFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(referenceIntegrityFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) == 0 {
+			continue
+		}
+		if _, ok := f.tagNames[tag[0]]; !ok {
+			continue
+		}
+
+		var value string
+		if len(tag) > 1 {
+			value = tag[1]
+		}
+
+		var valid bool
+		switch tag[0] {
+		case "p", "P":
+			valid = nostr.IsValidPublicKey(value)
+		default:
+			valid = nostr.IsValid32ByteHex(value)
+		}
+		if !valid {
+			return newResult(false, fmt.Sprintf("malformed_tag_reference:'%s'", tag[0]), nil)
+		}
+	}
+
+	return newResult(true, "tag_references_ok", nil)
+}
@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"strings"
+)
+
+// Machine-readable prefixes defined by NIP-01 for OK and CLOSED messages.
+const (
+	PrefixBlocked     = "blocked"
+	PrefixRateLimited = "rate-limited"
+	PrefixInvalid     = "invalid"
+	PrefixPoW         = "pow"
+	PrefixError       = "error"
+)
+
+// RejectionError is a rejected FilterResult expressed as an error, so relays
+// can emit NIP-01 OK messages with the proper machine-readable prefix.
+type RejectionError struct {
+	Prefix  string
+	Code    string
+	Filter  string
+	Message string
+}
+
+func (e *RejectionError) Error() string {
+	return e.Prefix + ": " + e.Message
+}
+
+// Err returns nil for accepted results and a *RejectionError otherwise.
+// The error is errors.As-compatible.
+func (r FilterResult) Err() error {
+	if r.Allowed {
+		return nil
+	}
+	code, _, _ := strings.Cut(r.Reason, ":")
+	message := r.Reason
+	if r.Message != "" {
+		message = r.Message
+	}
+	return &RejectionError{
+		Prefix:  rejectionPrefix(code),
+		Code:    code,
+		Filter:  r.Filter,
+		Message: message,
+	}
+}
+
+func rejectionPrefix(code string) string {
+	switch {
+	case strings.HasPrefix(code, "internal_"):
+		return PrefixError
+	case strings.HasPrefix(code, "rate_limit"),
+		strings.HasPrefix(code, "new_pubkey_rate_limit"),
+		strings.HasPrefix(code, "quota_"),
+		code == "profile_update_too_frequent",
+		code == "posting_too_frequently":
+		return PrefixRateLimited
+	case strings.Contains(code, "invalid"),
+		strings.Contains(code, "malformed"),
+		strings.HasPrefix(code, "missing_"),
+		strings.HasPrefix(code, "event_too_"),
+		code == "event_in_future":
+		return PrefixInvalid
+	case strings.Contains(code, "pow"):
+		return PrefixPoW
+	default:
+		return PrefixBlocked
+	}
+}
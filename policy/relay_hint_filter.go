@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	relayHintFilterName = "RelayHintFilter"
+)
+
+type RelayHintFilter struct {
+	filterBase
+
+	cfg     *config.RelayHintFilterConfig
+	kinds   map[int]struct{}
+	allowed map[string]struct{}
+}
+
+func NewRelayHintFilter(cfg *config.RelayHintFilterConfig) (*RelayHintFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	allowed := make(map[string]struct{}, len(cfg.AllowedHints))
+	for _, hint := range cfg.AllowedHints {
+		if normalized := normalizeRelayHint(hint); normalized != "" {
+			allowed[normalized] = struct{}{}
+		}
+	}
+
+	filter := &RelayHintFilter{
+		cfg:     cfg,
+		kinds:   kinds,
+		allowed: allowed,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *RelayHintFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(relayHintFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || (tag[0] != "e" && tag[0] != "p") {
+			continue
+		}
+
+		// The relay hint is positional: always the third element.
+		var hint string
+		if len(tag) > 2 {
+			hint = strings.TrimSpace(tag[2])
+		}
+
+		if hint == "" {
+			if f.cfg.RequireHint && tag[0] == "e" {
+				return newResult(false, "relay_hint_missing:'e'", nil)
+			}
+			continue
+		}
+
+		if len(f.allowed) > 0 {
+			if _, ok := f.allowed[normalizeRelayHint(hint)]; !ok {
+				reason := fmt.Sprintf("relay_hint_not_allowed:'%s','%s'", tag[0], hint)
+				return newResult(false, reason, nil)
+			}
+		}
+	}
+
+	return newResult(true, "relay_hints_ok", nil)
+}
+
+// normalizeRelayHint canonicalizes a relay URL and returns an empty string
+// unless it uses the ws or wss scheme.
+func normalizeRelayHint(hint string) string {
+	normalized := nostr.NormalizeURL(hint)
+	if !strings.HasPrefix(normalized, "wss://") && !strings.HasPrefix(normalized, "ws://") {
+		return ""
+	}
+	return normalized
+}
@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	relayListFilterName = "RelayListFilter"
+)
+
+// RelayListFilter validates NIP-65 relay lists (kind 10002): every `r` tag
+// must hold a ws:// or wss:// URL, the list may hold at most MaxRelays
+// entries and, with ValidateMarkers, markers must be "read" or "write".
+type RelayListFilter struct {
+	filterBase
+
+	cfg *config.RelayListFilterConfig
+}
+
+func NewRelayListFilter(cfg *config.RelayListFilterConfig) (*RelayListFilter, error) {
+	filter := &RelayListFilter{cfg: cfg}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *RelayListFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(relayListFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if event.Kind != nostr.KindRelayListMetadata {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	relays := 0
+	for _, tag := range event.Tags {
+		if len(tag) == 0 || tag[0] != "r" {
+			continue
+		}
+		relays++
+		if len(tag) < 2 || !isRelayURL(tag[1]) {
+			var value string
+			if len(tag) > 1 {
+				value = tag[1]
+			}
+			return newResult(false, fmt.Sprintf("malformed_relay_url:'%s'", value), nil)
+		}
+		if f.cfg.ValidateMarkers && len(tag) > 2 && tag[2] != "read" && tag[2] != "write" {
+			return newResult(false, fmt.Sprintf("invalid_relay_marker:'%s','%s'", tag[1], tag[2]), nil)
+		}
+	}
+
+	if f.cfg.MaxRelays > 0 && relays > f.cfg.MaxRelays {
+		reason := fmt.Sprintf("too_many_relays:got_%d,max_%d", relays, f.cfg.MaxRelays)
+		return newResult(false, reason, nil)
+	}
+	return newResult(true, "relay_list_ok", nil)
+}
+
+// isRelayURL reports whether s is an absolute ws:// or wss:// URL with a host.
+func isRelayURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "ws" || u.Scheme == "wss") && u.Host != ""
+}
@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	replyDepthFilterName = "ReplyDepthFilter"
+)
+
+// ReplyDepthLookup returns the reply depth of a stored event (0 for a root)
+// and whether the event is known.
+type ReplyDepthLookup func(id string) (int, bool)
+
+// ReplyDepthFilter rejects replies nested deeper than MaxDepth. In heuristic
+// mode the depth is the number of `e` tags, which NIP-10 clients grow by one
+// per level. In lookup mode it is the depth of the replied-to event plus
+// one, falling back to the heuristic when the parent is unknown.
+type ReplyDepthFilter struct {
+	filterBase
+
+	cfg    *config.ReplyDepthFilterConfig
+	kinds  map[int]struct{}
+	lookup ReplyDepthLookup
+}
+
+// NewReplyDepthFilter builds a ReplyDepthFilter. Kinds default to kind 1,
+// and lookup is required in lookup mode.
+func NewReplyDepthFilter(cfg *config.ReplyDepthFilterConfig, lookup ReplyDepthLookup) (*ReplyDepthFilter, error) {
+	if cfg.Enabled && cfg.Mode == config.ReplyDepthLookup && lookup == nil {
+		return nil, errors.New("reply depth filter in lookup mode but lookup is nil")
+	}
+
+	kindList := cfg.Kinds
+	if len(kindList) == 0 {
+		kindList = []int{nostr.KindTextNote}
+	}
+	kinds := make(map[int]struct{}, len(kindList))
+	for _, k := range kindList {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &ReplyDepthFilter{
+		cfg:    cfg,
+		kinds:  kinds,
+		lookup: lookup,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ReplyDepthFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(replyDepthFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxDepth <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if _, ok := f.kinds[event.Kind]; !ok {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	depth := f.depth(event)
+	if meta != nil {
+		meta["reply_depth"] = depth
+	}
+	if depth > f.cfg.MaxDepth {
+		reason := fmt.Sprintf("reply_chain_too_deep:depth_%d,max_%d", depth, f.cfg.MaxDepth)
+		return newResult(false, reason, nil)
+	}
+	return newResult(true, "reply_depth_ok", nil)
+}
+
+// depth estimates the reply depth of event. The parent is the marked reply,
+// else the last unmarked `e` tag (deprecated positional form), else the
+// root for a direct reply to it.
+func (f *ReplyDepthFilter) depth(event *nostr.Event) int {
+	var eTags int
+	var reply, positional, root string
+	for _, tag := range event.Tags {
+		if len(tag) < 2 || tag[0] != "e" {
+			continue
+		}
+		eTags++
+		var marker string
+		if len(tag) >= 4 {
+			marker = tag[3]
+		}
+		switch marker {
+		case "reply":
+			reply = tag[1]
+		case "root":
+			root = tag[1]
+		case "":
+			positional = tag[1]
+		}
+	}
+
+	if f.cfg.Mode == config.ReplyDepthLookup {
+		parent := reply
+		if parent == "" {
+			parent = positional
+		}
+		if parent == "" {
+			parent = root
+		}
+		if parent != "" {
+			if parentDepth, ok := f.lookup(parent); ok {
+				return parentDepth + 1
+			}
+		}
+	}
+	return eTags
+}
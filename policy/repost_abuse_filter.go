@@ -2,8 +2,13 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"regexp"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +17,7 @@ import (
 	"github.com/nbd-wtf/go-nostr"
 
 	"github.com/lessucettes/adresu-kit/config"
+	"github.com/lessucettes/adresu-kit/nip"
 )
 
 const (
@@ -19,18 +25,21 @@ const (
 )
 
 type UserActivityStats struct {
-	OriginalPosts int
-	Reposts       int
-	LastEventTime time.Time
+	OriginalPosts      int       `json:"original_posts"`
+	Reposts            int       `json:"reposts"`
+	ConsecutiveReposts int       `json:"consecutive_reposts"`
+	LastEventTime      time.Time `json:"last_event_time"`
 }
 
 type RepostAbuseFilter struct {
-	mu    sync.Mutex
-	stats *lru.LRU[string, *UserActivityStats]
-	cfg   *config.RepostAbuseFilterConfig
-}
+	filterBase
 
-var nip21Re = regexp.MustCompile(`\b(naddr1|nevent1|note1)[0-9a-z]+\b`)
+	mu      sync.Mutex
+	closed  bool
+	stats   *lru.LRU[string, *UserActivityStats]
+	targets *lru.LRU[string, int]
+	cfg     *config.RepostAbuseFilterConfig
+}
 
 func NewRepostAbuseFilter(cfg *config.RepostAbuseFilterConfig) (*RepostAbuseFilter, error) {
 	size := cfg.CacheSize
@@ -46,39 +55,216 @@ func NewRepostAbuseFilter(cfg *config.RepostAbuseFilterConfig) (*RepostAbuseFilt
 		stats: cache,
 		cfg:   cfg,
 	}
+	if cfg.MaxRepeatRepostsPerTarget > 0 {
+		window := cfg.RepeatRepostWindow
+		if window <= 0 {
+			window = cfg.CacheTTL
+		}
+		filter.targets = lru.NewLRU[string, int](size, nil, window)
+	}
 
+	if cfg.StatePath != "" {
+		file, err := os.Open(cfg.StatePath)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("failed to open repost abuse state: %w", err)
+		default:
+			err = filter.Load(file)
+			file.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
 	return filter, nil
 }
 
+// Save writes the per-pubkey activity stats to w as JSON.
+func (f *RepostAbuseFilter) Save(w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.saveLocked(w)
+}
+
+// saveLocked implements Save. The caller must hold f.mu.
+func (f *RepostAbuseFilter) saveLocked(w io.Writer) error {
+	snapshot := make(map[string]UserActivityStats, f.stats.Len())
+	for _, pubkey := range f.stats.Keys() {
+		if stats, ok := f.stats.Peek(pubkey); ok && stats != nil {
+			snapshot[pubkey] = *stats
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to encode repost abuse state: %w", err)
+	}
+	return nil
+}
+
+// Load merges stats previously written by Save into the cache. Loaded entries
+// start a fresh CacheTTL.
+func (f *RepostAbuseFilter) Load(r io.Reader) error {
+	var snapshot map[string]UserActivityStats
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode repost abuse state: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for pubkey, stats := range snapshot {
+		f.stats.Add(pubkey, &stats)
+	}
+	return nil
+}
+
+// Close saves the stats to StatePath, if configured, and releases the cache.
+// The file is replaced atomically so a crash mid-write leaves the previous
+// snapshot intact. The cache is kept if saving fails, so Close can be
+// retried; once it succeeds, later calls are no-ops and never overwrite the
+// saved state with an empty snapshot.
+func (f *RepostAbuseFilter) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	if f.cfg.StatePath == "" {
+		purgeCache(f.stats)
+		purgeCache(f.targets)
+		f.closed = true
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.cfg.StatePath), filepath.Base(f.cfg.StatePath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create repost abuse state file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := f.saveLocked(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write repost abuse state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.cfg.StatePath); err != nil {
+		return fmt.Errorf("failed to replace repost abuse state: %w", err)
+	}
+	purgeCache(f.stats)
+	purgeCache(f.targets)
+	f.closed = true
+	return nil
+}
+
 func (f *RepostAbuseFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(repostAbuseFilterName)
+	newResult := f.resultFunc(repostAbuseFilterName, event, meta)
 
 	if !f.cfg.Enabled {
 		return newResult(true, "filter_disabled", nil)
 	}
-	if event.Kind != nostr.KindTextNote && event.Kind != nostr.KindRepost && event.Kind != nostr.KindGenericRepost {
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if !isRepostAbuseKind(event.Kind) {
 		return newResult(true, "kind_not_checked", nil)
 	}
 
+	isRepost, _ := f.isRepostNIP18(event)
+
 	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(event, isRepost)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// MatchBatch evaluates events in order under a single lock acquisition, so
+// ratios evolve exactly as they would with sequential Match calls. Decision
+// hooks run after the lock is released.
+func (f *RepostAbuseFilter) MatchBatch(_ context.Context, events []*nostr.Event, metas []map[string]any) ([]FilterResult, []error) {
+	results := make([]FilterResult, len(events))
+	errs := make([]error, len(events))
+
+	batchMetas := make([]map[string]any, len(events))
+	copy(batchMetas, metas)
+
+	// Result funcs start their timers here, as in Match, so each Duration
+	// covers the batch work done before the event's decision is reported.
+	newResults := make([]func(bool, string, error, ...MessageData) (FilterResult, error), len(events))
+	for i, event := range events {
+		newResults[i] = f.resultFunc(repostAbuseFilterName, event, batchMetas[i])
+	}
+
+	isRepost := make([]bool, len(events))
+	for i, event := range events {
+		isRepost[i], _ = f.isRepostNIP18(event)
+	}
+
+	allowed := make([]bool, len(events))
+	reasons := make([]string, len(events))
+
+	f.mu.Lock()
+	for i, event := range events {
+		switch {
+		case !f.cfg.Enabled:
+			allowed[i], reasons[i] = true, "filter_disabled"
+		case isTrusted(batchMetas[i]):
+			allowed[i], reasons[i] = true, "pubkey_trusted"
+		case !isRepostAbuseKind(event.Kind):
+			allowed[i], reasons[i] = true, "kind_not_checked"
+		default:
+			allowed[i], reasons[i] = f.evaluateLocked(event, isRepost[i])
+		}
+	}
+	f.mu.Unlock()
+
+	for i := range events {
+		results[i], errs[i] = newResults[i](allowed[i], reasons[i], nil)
+	}
+
+	return results, errs
+}
+
+// evaluateLocked applies the ratio check and updates the author's stats.
+// The caller must hold f.mu.
+func (f *RepostAbuseFilter) evaluateLocked(event *nostr.Event, isRepost bool) (bool, string) {
 	stats, ok := f.stats.Get(event.PubKey)
 	if !ok || stats == nil {
 		stats = &UserActivityStats{}
 	} else if f.cfg.ResetDuration > 0 && !stats.LastEventTime.IsZero() {
-		if time.Since(stats.LastEventTime) > f.cfg.ResetDuration {
-			stats.OriginalPosts, stats.Reposts = 0, 0
+		if f.now().Sub(stats.LastEventTime) > f.cfg.ResetDuration {
+			stats.OriginalPosts, stats.Reposts, stats.ConsecutiveReposts = 0, 0, 0
 		}
 	}
-	statsCopy := *stats
-	f.mu.Unlock()
 
-	isRepost, _ := f.isRepostNIP18(event)
 	var rejectionReason string
 
-	if isRepost {
-		total := statsCopy.OriginalPosts + statsCopy.Reposts
+	// Repeated reposts of one target are limited independently of the ratio.
+	var targetKey string
+	if isRepost && f.targets != nil {
+		if target := repostTarget(event); target != "" {
+			targetKey = event.PubKey + ":" + target
+			if count, _ := f.targets.Get(targetKey); count >= f.cfg.MaxRepeatRepostsPerTarget {
+				rejectionReason = fmt.Sprintf("repeated_repost_of_target:count_%d,max_%d", count, f.cfg.MaxRepeatRepostsPerTarget)
+			}
+		}
+	}
+
+	if isRepost && rejectionReason == "" && f.cfg.MaxConsecutiveReposts > 0 {
+		if stats.ConsecutiveReposts+1 > f.cfg.MaxConsecutiveReposts {
+			rejectionReason = fmt.Sprintf("too_many_consecutive_reposts:max_%d", f.cfg.MaxConsecutiveReposts)
+		}
+	}
+
+	if isRepost && rejectionReason == "" {
+		total := stats.OriginalPosts + stats.Reposts
 		if total >= f.cfg.MinEvents {
-			predictedReposts := statsCopy.Reposts + 1
+			predictedReposts := stats.Reposts + 1
 			predictedTotal := total + 1
 			var currentRatio float64
 			if predictedTotal > 0 {
@@ -95,33 +281,52 @@ func (f *RepostAbuseFilter) Match(_ context.Context, event *nostr.Event, meta ma
 		}
 	}
 
-	f.mu.Lock()
-	fresh, ok := f.stats.Get(event.PubKey)
-	if !ok || fresh == nil {
-		fresh = &UserActivityStats{}
-	}
-	if f.cfg.ResetDuration > 0 && !fresh.LastEventTime.IsZero() {
-		if time.Since(fresh.LastEventTime) > f.cfg.ResetDuration {
-			fresh.OriginalPosts, fresh.Reposts = 0, 0
-		}
-	}
 	if rejectionReason == "" || f.cfg.CountRejectAsActivity {
-		fresh.LastEventTime = time.Now()
+		stats.LastEventTime = f.now()
+	}
+	if rejectionReason == "" && targetKey != "" {
+		count, _ := f.targets.Get(targetKey)
+		f.targets.Add(targetKey, count+1)
 	}
 	if rejectionReason == "" {
 		if isRepost {
-			fresh.Reposts++
+			stats.Reposts++
+			stats.ConsecutiveReposts++
 		} else {
-			fresh.OriginalPosts++
+			stats.ConsecutiveReposts = 0
+			// With ForgiveOnOriginal, each original post also pays down
+			// earlier reposts so the ratio recovers immediately.
+			if f.cfg.ForgiveOnOriginal {
+				stats.Reposts = max(stats.Reposts-max(f.cfg.ForgiveAmount, 1), 0)
+			}
+			stats.OriginalPosts++
 		}
 	}
-	f.stats.Add(event.PubKey, fresh)
-	f.mu.Unlock()
+	f.stats.Add(event.PubKey, stats)
 
 	if rejectionReason != "" {
-		return newResult(false, rejectionReason, nil)
+		return false, rejectionReason
 	}
-	return newResult(true, "repost_ratio_ok", nil)
+	return true, "repost_ratio_ok"
+}
+
+// repostTarget returns the id of the reposted or quoted event.
+func repostTarget(ev *nostr.Event) string {
+	name := "e"
+	if ev.Kind == nostr.KindTextNote {
+		name = "q"
+	}
+	if tag := ev.Tags.Find(name); tag != nil {
+		return tag[1]
+	}
+	if tag := ev.Tags.Find("a"); tag != nil {
+		return tag[1]
+	}
+	return ""
+}
+
+func isRepostAbuseKind(kind int) bool {
+	return kind == nostr.KindTextNote || kind == nostr.KindRepost || kind == nostr.KindGenericRepost
 }
 
 func (f *RepostAbuseFilter) isRepostNIP18(ev *nostr.Event) (bool, string) {
@@ -150,5 +355,11 @@ func hasTag(ev *nostr.Event, tagName string) bool {
 }
 
 func contentHasNIP21Ref(s string) bool {
-	return nip21Re.MatchString(s)
+	for _, ref := range nip.ParseNostrRefs(s) {
+		switch ref.Type {
+		case "note", "nevent", "naddr":
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,46 @@
+package policy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// processSampleKey keys sampling decisions when no salt is configured. It is
+// random per process, so authors cannot grind event IDs that are always
+// sampled out.
+var processSampleKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("policy: failed to generate sampling key: " + err.Error())
+	}
+	return key
+}()
+
+// sampleKey returns the key for a configured sample salt, falling back to
+// the per-process random key when salt is empty.
+func sampleKey(salt string) []byte {
+	if salt == "" {
+		return processSampleKey
+	}
+	return []byte(salt)
+}
+
+// sampledOut reports whether an expensive check should be skipped for event
+// given a sample rate in (0, 1). The decision is a keyed hash of the event
+// ID, so it is unpredictable without the key; replicas sharing a configured
+// salt sample the same events. Rates outside that range, including the zero
+// value, disable sampling.
+func sampledOut(event *nostr.Event, sampleRate float64, key []byte) bool {
+	if sampleRate <= 0 || sampleRate >= 1 {
+		return false
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(event.ID))
+	sum := mac.Sum(nil)
+	return float64(binary.BigEndian.Uint64(sum[:8]))/math.MaxUint64 >= sampleRate
+}
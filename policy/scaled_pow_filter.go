@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+	"github.com/lessucettes/adresu-kit/nip"
+)
+
+const (
+	scaledPoWFilterName = "ScaledPoWFilter"
+	// An event ID has 256 bits, so no event can commit more work than this.
+	maxPoWDifficulty = 256
+)
+
+type ScaledPoWFilter struct {
+	filterBase
+
+	cfg   *config.ScaledPoWFilterConfig
+	kinds map[int]struct{}
+}
+
+func NewScaledPoWFilter(cfg *config.ScaledPoWFilterConfig) (*ScaledPoWFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &ScaledPoWFilter{
+		cfg:   cfg,
+		kinds: kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ScaledPoWFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(scaledPoWFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	raw, err := json.Marshal(event)
+	if err != nil {
+		// This is a critical error, propagate it to the pipeline.
+		return newResult(false, "internal_marshal_failed", err)
+	}
+	size := len(raw)
+
+	required := f.cfg.BaseDifficulty
+	if f.cfg.BytesPerBit > 0 {
+		required += size / f.cfg.BytesPerBit
+	}
+	required = min(required, maxPoWDifficulty)
+
+	if !nip.IsPoWValid(event, required) {
+		reason := fmt.Sprintf("pow_insufficient:required_%d,size_%d", required, size)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "pow_ok", nil)
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	scheduleFilterName = "ScheduleFilter"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+type scheduleWindow struct {
+	days       map[time.Weekday]struct{}
+	start, end int
+}
+
+// contains reports whether t falls in the window. A window whose end hour is
+// not after its start hour wraps past midnight; days refer to t's weekday.
+func (w scheduleWindow) contains(t time.Time) bool {
+	if len(w.days) > 0 {
+		if _, ok := w.days[t.Weekday()]; !ok {
+			return false
+		}
+	}
+	h := t.Hour()
+	if w.start < w.end {
+		return h >= w.start && h < w.end
+	}
+	return h >= w.start || h < w.end
+}
+
+type ScheduleFilter struct {
+	filterBase
+
+	cfg      *config.ScheduleFilterConfig
+	location *time.Location
+	windows  map[int][]scheduleWindow
+}
+
+func NewScheduleFilter(cfg *config.ScheduleFilterConfig) (*ScheduleFilter, error) {
+	warnDisabledWithRules(scheduleFilterName, cfg.Enabled, len(cfg.Rules))
+	if !cfg.Enabled {
+		return &ScheduleFilter{cfg: cfg}, nil
+	}
+
+	location := time.UTC
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule timezone %q: %w", cfg.Timezone, err)
+		}
+		location = loc
+	}
+
+	windows := make(map[int][]scheduleWindow)
+	for _, rule := range cfg.Rules {
+		compiled := make([]scheduleWindow, 0, len(rule.Windows))
+		for _, w := range rule.Windows {
+			if w.StartHour < 0 || w.StartHour > 23 || w.EndHour < 0 || w.EndHour > 24 {
+				return nil, fmt.Errorf("invalid hours %d-%d in schedule rule '%s'", w.StartHour, w.EndHour, rule.Description)
+			}
+			sw := scheduleWindow{start: w.StartHour, end: w.EndHour}
+			if len(w.Days) > 0 {
+				sw.days = make(map[time.Weekday]struct{}, len(w.Days))
+				for _, day := range w.Days {
+					wd, ok := weekdayNames[strings.ToLower(strings.TrimSpace(day))]
+					if !ok {
+						return nil, fmt.Errorf("invalid day %q in schedule rule '%s'", day, rule.Description)
+					}
+					sw.days[wd] = struct{}{}
+				}
+			}
+			compiled = append(compiled, sw)
+		}
+		for _, kind := range rule.Kinds {
+			windows[kind] = append(windows[kind], compiled...)
+		}
+	}
+
+	filter := &ScheduleFilter{
+		cfg:      cfg,
+		location: location,
+		windows:  windows,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ScheduleFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(scheduleFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	windows, ok := f.windows[event.Kind]
+	if !ok {
+		return newResult(true, "no_schedule_for_kind", nil)
+	}
+
+	t := event.CreatedAt.Time()
+	if f.cfg.UseArrivalTime {
+		t = f.now()
+	}
+	t = t.In(f.location)
+
+	for _, w := range windows {
+		if w.contains(t) {
+			return newResult(true, "within_schedule", nil)
+		}
+	}
+
+	return newResult(false, fmt.Sprintf("kind_outside_schedule:kind_%d", event.Kind), nil)
+}
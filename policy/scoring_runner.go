@@ -0,0 +1,90 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	scoringRunnerName = "ScoringRunner"
+)
+
+// ScoringRunner runs a set of filters and turns each rejection into a weighted
+// score instead of a hard failure. The event is rejected only when the total
+// score exceeds BlockThreshold. ScoringRunner itself implements Filter, so it
+// can be composed with other filters.
+type ScoringRunner struct {
+	filterBase
+
+	cfg     *config.ScoringRunnerConfig
+	filters []Filter
+}
+
+func NewScoringRunner(cfg *config.ScoringRunnerConfig, filters ...Filter) (*ScoringRunner, error) {
+	if len(filters) == 0 {
+		return nil, errors.New("scoring runner requires at least one filter")
+	}
+	if cfg.BlockThreshold <= 0 {
+		return nil, fmt.Errorf("scoring runner block_threshold must be positive, got %v", cfg.BlockThreshold)
+	}
+
+	runner := &ScoringRunner{
+		cfg:     cfg,
+		filters: filters,
+	}
+
+	runner.dryRun.Store(cfg.DryRun)
+	return runner, nil
+}
+
+// Match evaluates every wrapped filter and sums the weights of those that
+// reject. The score is stored in meta["spam_score"]. A filter error aborts
+// the run and is returned as is.
+func (r *ScoringRunner) Match(ctx context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := r.resultFunc(scoringRunnerName, event, meta)
+
+	var score float64
+	for _, f := range r.filters {
+		res, err := f.Match(ctx, event, meta)
+		if err != nil {
+			return newResult(false, "internal_scoring_filter_failed:"+res.Filter, err)
+		}
+		if !res.Allowed {
+			score += r.weight(res)
+		}
+	}
+
+	if meta != nil {
+		meta["spam_score"] = score
+	}
+
+	if score > r.cfg.BlockThreshold {
+		reason := fmt.Sprintf("spam_score_exceeded:score_%.2f,threshold_%.2f", score, r.cfg.BlockThreshold)
+		return newResult(false, reason, nil)
+	}
+	return newResult(true, fmt.Sprintf("spam_score_ok:score_%.2f", score), nil)
+}
+
+// weight looks up the weight of a rejection by its reason code first, then by
+// filter name, falling back to DefaultWeight.
+func (r *ScoringRunner) weight(res FilterResult) float64 {
+	code, _, _ := strings.Cut(res.Reason, ":")
+	if w, ok := r.cfg.ReasonWeights[code]; ok {
+		return w
+	}
+	if w, ok := r.cfg.FilterWeights[res.Filter]; ok {
+		return w
+	}
+	return r.cfg.DefaultWeight
+}
+
+// Close closes the wrapped filters.
+func (r *ScoringRunner) Close() error {
+	return CloseAll(r.filters...)
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	shadowBanFilterName = "ShadowBanFilter"
+)
+
+// ShadowBanFilter accepts events from shadow-banned pubkeys but marks them
+// with meta["shadow_banned"] so the relay can store them without broadcasting.
+type ShadowBanFilter struct {
+	filterBase
+
+	mu     sync.RWMutex
+	cfg    *config.ShadowBanFilterConfig
+	banned map[string]struct{}
+}
+
+func NewShadowBanFilter(cfg *config.ShadowBanFilterConfig) (*ShadowBanFilter, error) {
+	warnDisabledWithRules(shadowBanFilterName, cfg.Enabled, len(cfg.Pubkeys))
+
+	filter := &ShadowBanFilter{
+		cfg:    cfg,
+		banned: pubkeySet(cfg.Pubkeys),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// Add shadow-bans pubkey.
+func (f *ShadowBanFilter) Add(pubkey string) {
+	f.mu.Lock()
+	f.banned[pubkey] = struct{}{}
+	f.mu.Unlock()
+}
+
+// Remove lifts the shadow ban on pubkey.
+func (f *ShadowBanFilter) Remove(pubkey string) {
+	f.mu.Lock()
+	delete(f.banned, pubkey)
+	f.mu.Unlock()
+}
+
+// List returns the shadow-banned pubkeys in sorted order.
+func (f *ShadowBanFilter) List() []string {
+	f.mu.RLock()
+	list := make([]string, 0, len(f.banned))
+	for pk := range f.banned {
+		list = append(list, pk)
+	}
+	f.mu.RUnlock()
+
+	slices.Sort(list)
+	return list
+}
+
+func (f *ShadowBanFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(shadowBanFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	f.mu.RLock()
+	_, banned := f.banned[event.PubKey]
+	f.mu.RUnlock()
+
+	if !banned {
+		return newResult(true, "pubkey_not_shadow_banned", nil)
+	}
+	if meta != nil {
+		meta["shadow_banned"] = true
+	}
+	return newResult(true, "pubkey_shadow_banned", nil)
+}
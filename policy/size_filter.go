@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
 
 	"github.com/nbd-wtf/go-nostr"
 
@@ -14,37 +17,106 @@ const (
 	sizeFilterName = "SizeFilter"
 )
 
+type sizeRuleSet struct {
+	enabled        bool
+	defaultMaxSize int
+	kindToRule     map[int]*config.SizeRule
+	kindToLabel    map[int]string
+	excludeFixed   bool
+}
+
 type SizeFilter struct {
-	cfg        *config.SizeFilterConfig
-	kindToRule map[int]*config.SizeRule
+	filterBase
+
+	rules atomic.Pointer[sizeRuleSet]
 }
 
 func NewSizeFilter(cfg *config.SizeFilterConfig) (*SizeFilter, error) {
-	kindMap := make(map[int]*config.SizeRule)
+	filter := &SizeFilter{}
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+	return filter, nil
+}
+
+// Reload atomically replaces the size rules and message templates and returns
+// the config warnings that the constructor logs. On error the previous
+// configuration stays active. The filter holds no other state.
+func (f *SizeFilter) Reload(cfg *config.SizeFilterConfig) ([]string, error) {
+	var messages messageTemplates
+	if cfg != nil {
+		var err error
+		if messages, err = compileMessages(sizeFilterName, cfg.Messages); err != nil {
+			return nil, err
+		}
+	}
+
+	rules, warnings := compileSizeRules(cfg)
+	f.rules.Store(rules)
+	f.setMessages(messages)
+	f.dryRun.Store(cfg != nil && cfg.DryRun)
+	return warnings, nil
+}
 
+func compileSizeRules(cfg *config.SizeFilterConfig) (*sizeRuleSet, []string) {
+	rules := &sizeRuleSet{
+		kindToRule:  make(map[int]*config.SizeRule),
+		kindToLabel: make(map[int]string),
+	}
+
+	var warnings []string
 	if cfg != nil {
+		// A default limit is a rule too: configs written before the Enabled
+		// gate existed may set nothing else.
+		configured := len(cfg.Rules)
+		if cfg.DefaultMaxSize > 0 {
+			configured++
+		}
+		warnings = disabledWithRulesWarning(sizeFilterName, cfg.Enabled, configured)
+		rules.enabled = cfg.Enabled
+		rules.defaultMaxSize = cfg.DefaultMaxSize
+		rules.excludeFixed = cfg.ExcludeEnvelopeOverhead
 		for i := range cfg.Rules {
 			rule := &cfg.Rules[i]
 			for _, kind := range rule.Kinds {
-				kindMap[kind] = rule
+				rules.kindToRule[kind] = rule
+				rules.kindToLabel[kind] = ruleLabel(rule.Description, i)
 			}
 		}
 	}
 
-	filter := &SizeFilter{cfg: cfg, kindToRule: kindMap}
-	return filter, nil
+	return rules, warnings
 }
 
 func (f *SizeFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(sizeFilterName)
+	newResult := f.resultFunc(sizeFilterName, event, meta)
+	rules := f.rules.Load()
 
-	maxSize := 0
-	if f.cfg != nil {
-		maxSize = f.cfg.DefaultMaxSize
+	if !rules.enabled {
+		return newResult(true, "filter_disabled", nil)
 	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	maxSize := rules.defaultMaxSize
+	maxRunes := 0
 
-	if rule, ok := f.kindToRule[event.Kind]; ok {
+	if rule, ok := rules.kindToRule[event.Kind]; ok {
 		maxSize = rule.MaxSize
+		maxRunes = rule.MaxContentRunes
+		setMatchedRule(meta, rules.kindToLabel[event.Kind])
+	} else {
+		setMatchedRule(meta, "default")
+	}
+
+	if maxRunes > 0 {
+		if runes := utf8.RuneCountInString(event.Content); runes > maxRunes {
+			reason := fmt.Sprintf("content_too_long:runes_%d,max_%d", runes, maxRunes)
+			return newResult(false, reason, nil, MessageData{Limit: maxRunes, Got: runes})
+		}
 	}
 
 	if maxSize <= 0 {
@@ -57,11 +129,29 @@ func (f *SizeFilter) Match(_ context.Context, event *nostr.Event, meta map[strin
 		return newResult(false, "internal_marshal_failed", err)
 	}
 	size := len(raw)
+	if rules.excludeFixed {
+		size = max(size-envelopeOverhead(event), 0)
+	}
 
 	if size > maxSize {
 		reason := fmt.Sprintf("event_too_large:size_%d,max_%d", size, maxSize)
-		return newResult(false, reason, nil)
+		return newResult(false, reason, nil, MessageData{Limit: maxSize, Got: size})
 	}
 
 	return newResult(true, "size_ok", nil)
 }
+
+// envelopeOverhead returns the bytes the marshaled event spends on its fixed
+// fields: the "id", "pubkey", "sig", "created_at" and "kind" members, each
+// with its key, value, and separating comma. What remains is the braces,
+// tags and content, which is the part the author controls.
+func envelopeOverhead(ev *nostr.Event) int {
+	member := func(key string, valueLen int) int {
+		return len(key) + 4 + valueLen // quoted key, colon, comma
+	}
+	return member("id", len(ev.ID)+2) +
+		member("pubkey", len(ev.PubKey)+2) +
+		member("sig", len(ev.Sig)+2) +
+		member("created_at", len(strconv.FormatInt(int64(ev.CreatedAt), 10))) +
+		member("kind", len(strconv.Itoa(ev.Kind)))
+}
@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	structuralFilterName = "StructuralFilter"
+)
+
+// StructuralFilter performs cheap shape checks on the event envelope so that
+// obvious garbage is rejected before the expensive signature check.
+type StructuralFilter struct {
+	filterBase
+
+	cfg *config.StructuralFilterConfig
+}
+
+func NewStructuralFilter(cfg *config.StructuralFilterConfig) (*StructuralFilter, error) {
+	filter := &StructuralFilter{cfg: cfg}
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *StructuralFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(structuralFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+
+	switch {
+	case !isLowerHexOfLength(event.ID, 64):
+		return newResult(false, "invalid_event_id", nil)
+	case !isLowerHexOfLength(event.PubKey, 64):
+		return newResult(false, "invalid_pubkey", nil)
+	case !isLowerHexOfLength(event.Sig, 128):
+		return newResult(false, "invalid_signature_format", nil)
+	case event.Kind < 0:
+		return newResult(false, "invalid_kind", nil)
+	case event.CreatedAt <= 0:
+		return newResult(false, "invalid_created_at", nil)
+	}
+
+	return newResult(true, "structure_valid", nil)
+}
+
+func isLowerHexOfLength(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	structureFilterName = "StructureFilter"
+)
+
+// StructureFilter limits the number of lines in content and the length of
+// each line in runes, which catches ASCII art and walls of text.
+type StructureFilter struct {
+	filterBase
+
+	cfg   *config.StructureFilterConfig
+	kinds map[int]struct{}
+}
+
+func NewStructureFilter(cfg *config.StructureFilterConfig) (*StructureFilter, error) {
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	filter := &StructureFilter{
+		cfg:   cfg,
+		kinds: kinds,
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *StructureFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(structureFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	lines := strings.Split(event.Content, "\n")
+	if f.cfg.MaxLines > 0 && len(lines) > f.cfg.MaxLines {
+		reason := fmt.Sprintf("too_many_lines:got_%d,max_%d", len(lines), f.cfg.MaxLines)
+		return newResult(false, reason, nil)
+	}
+
+	if f.cfg.MaxLineLength > 0 {
+		for _, line := range lines {
+			if n := utf8.RuneCountInString(line); n > f.cfg.MaxLineLength {
+				reason := fmt.Sprintf("line_too_long:length_%d,max_%d", n, f.cfg.MaxLineLength)
+				return newResult(false, reason, nil)
+			}
+		}
+	}
+
+	return newResult(true, "structure_ok", nil)
+}
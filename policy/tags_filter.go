@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"maps"
+	"slices"
+	"strings"
+	"sync/atomic"
 
 	"github.com/nbd-wtf/go-nostr"
 
@@ -14,31 +17,105 @@ const (
 	tagsFilterName = "TagsFilter"
 )
 
-type TagsFilter struct{ kindToRule map[int]processedTagRule }
+type tagRuleSet struct {
+	enabled        bool
+	kindToRule     map[int]processedTagRule
+	normalizeNames bool
+}
+
+type TagsFilter struct {
+	filterBase
+
+	rules atomic.Pointer[tagRuleSet]
+}
+
+type conditionalTagReq struct {
+	ifTag       string
+	thenRequire []string
+}
 
 type processedTagRule struct {
 	source       *config.TagRule
+	label        string
 	requiredTags map[string]struct{}
 	maxTagCounts map[string]int
+	conditionals []conditionalTagReq
+	allowedVals  map[string]map[string]struct{}
+	uniqueVals   map[string]struct{}
 }
 
 func NewTagsFilter(cfg *config.TagsFilterConfig) (*TagsFilter, error) {
+	filter := &TagsFilter{}
+	warnings, err := filter.Reload(cfg)
+	if err != nil {
+		return nil, err
+	}
+	logWarnings(warnings)
+	return filter, nil
+}
+
+// Reload atomically replaces the compiled tag rules and returns the config
+// warnings that the constructor logs. In-flight Match calls finish against
+// the rules they started with. The filter holds no other state.
+func (f *TagsFilter) Reload(cfg *config.TagsFilterConfig) ([]string, error) {
+	rules, warnings := compileTagRules(cfg)
+	f.rules.Store(rules)
+	f.dryRun.Store(cfg != nil && cfg.DryRun)
+	return warnings, nil
+}
+
+func compileTagRules(cfg *config.TagsFilterConfig) (*tagRuleSet, []string) {
 	kindMap := make(map[int]processedTagRule)
+	normalize := cfg != nil && cfg.NormalizeTagNames
+	enabled := cfg != nil && cfg.Enabled
+	var warnings []string
 	if cfg != nil {
+		warnings = disabledWithRulesWarning(tagsFilterName, cfg.Enabled, len(cfg.Rules))
 		for i := range cfg.Rules {
 			rule := &cfg.Rules[i]
 			processed := processedTagRule{
 				source:       rule,
+				label:        ruleLabel(rule.Description, i),
 				requiredTags: make(map[string]struct{}),
 				maxTagCounts: make(map[string]int),
+				allowedVals:  make(map[string]map[string]struct{}),
+				uniqueVals:   make(map[string]struct{}, len(rule.UniqueValueTags)),
 			}
 			if len(rule.RequiredTags) > 0 {
 				for _, req := range rule.RequiredTags {
-					processed.requiredTags[req] = struct{}{}
+					processed.requiredTags[normalizeTagName(req, normalize)] = struct{}{}
+				}
+			}
+			// With NormalizeTagNames, "T" and "t" share a key; keep the
+			// smaller limit so the result does not depend on map order.
+			collided := make(map[string]struct{})
+			for name, limit := range rule.MaxTagCounts {
+				key := normalizeTagName(name, normalize)
+				if existing, ok := processed.maxTagCounts[key]; ok {
+					collided[key] = struct{}{}
+					limit = min(existing, limit)
+				}
+				processed.maxTagCounts[key] = limit
+			}
+			for _, key := range slices.Sorted(maps.Keys(collided)) {
+				warnings = append(warnings, fmt.Sprintf("%s config warning: rule '%s' sets max_tag_counts for several names that normalize to '%s'; the smallest limit %d applies", tagsFilterName, processed.label, key, processed.maxTagCounts[key]))
+			}
+			for _, cond := range rule.ConditionalRequirements {
+				compiled := conditionalTagReq{ifTag: normalizeTagName(cond.IfTag, normalize)}
+				for _, req := range cond.ThenRequire {
+					compiled.thenRequire = append(compiled.thenRequire, normalizeTagName(req, normalize))
+				}
+				processed.conditionals = append(processed.conditionals, compiled)
+			}
+			for name, values := range rule.AllowedTagValues {
+				set := make(map[string]struct{}, len(values))
+				for _, v := range values {
+					set[v] = struct{}{}
 				}
+				processed.allowedVals[normalizeTagName(name, normalize)] = set
 			}
-			if len(rule.MaxTagCounts) > 0 {
-				maps.Copy(processed.maxTagCounts, rule.MaxTagCounts)
+			for _, name := range rule.UniqueValueTags {
+				processed.uniqueVals[normalizeTagName(name, normalize)] = struct{}{}
 			}
 			for _, kind := range rule.Kinds {
 				kindMap[kind] = processed
@@ -46,24 +123,58 @@ func NewTagsFilter(cfg *config.TagsFilterConfig) (*TagsFilter, error) {
 		}
 	}
 
-	filter := &TagsFilter{kindToRule: kindMap}
-	return filter, nil
+	return &tagRuleSet{enabled: enabled, kindToRule: kindMap, normalizeNames: normalize}, warnings
 }
 
 func (f *TagsFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
-	newResult := NewResultFunc(tagsFilterName)
+	newResult := f.resultFunc(tagsFilterName, event, meta)
+
+	rules := f.rules.Load()
+	if !rules.enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
 
-	processedRule, exists := f.kindToRule[event.Kind]
+	processedRule, exists := rules.kindToRule[event.Kind]
 	if !exists {
 		return newResult(true, "no_rules_for_kind", nil)
 	}
 	rule := processedRule.source
+	setMatchedRule(meta, processedRule.label)
 
 	if rule.MaxTags != nil && len(event.Tags) > *rule.MaxTags {
 		reason := fmt.Sprintf("too_many_tags:got_%d,max_%d", len(event.Tags), *rule.MaxTags)
 		return newResult(false, reason, nil)
 	}
 
+	if rule.MaxTotalTagBytes > 0 {
+		total := 0
+		for _, tag := range event.Tags {
+			for _, v := range tag {
+				total += len(v)
+			}
+		}
+		if total > rule.MaxTotalTagBytes {
+			reason := fmt.Sprintf("tags_too_large:size_%d,max_%d", total, rule.MaxTotalTagBytes)
+			return newResult(false, reason, nil)
+		}
+	}
+
+	if rule.MaxDistinctTagNames > 0 {
+		names := make(map[string]struct{})
+		for _, tag := range event.Tags {
+			if len(tag) > 0 {
+				names[normalizeTagName(tag[0], rules.normalizeNames)] = struct{}{}
+			}
+		}
+		if len(names) > rule.MaxDistinctTagNames {
+			reason := fmt.Sprintf("too_many_distinct_tag_names:got_%d,max_%d", len(names), rule.MaxDistinctTagNames)
+			return newResult(false, reason, nil)
+		}
+	}
+
 	if len(processedRule.requiredTags) > 0 || len(processedRule.maxTagCounts) > 0 {
 		requiredFound := make(map[string]bool, len(processedRule.requiredTags))
 		specificTagCounts := make(map[string]int, len(processedRule.maxTagCounts))
@@ -72,7 +183,7 @@ func (f *TagsFilter) Match(_ context.Context, event *nostr.Event, meta map[strin
 			if len(tag) == 0 {
 				continue
 			}
-			tagName := tag[0]
+			tagName := normalizeTagName(tag[0], rules.normalizeNames)
 
 			if _, ok := processedRule.maxTagCounts[tagName]; ok {
 				specificTagCounts[tagName]++
@@ -98,5 +209,74 @@ func (f *TagsFilter) Match(_ context.Context, event *nostr.Event, meta map[strin
 		}
 	}
 
+	if len(processedRule.allowedVals) > 0 {
+		for _, tag := range event.Tags {
+			if len(tag) == 0 {
+				continue
+			}
+			tagName := normalizeTagName(tag[0], rules.normalizeNames)
+			allowed, ok := processedRule.allowedVals[tagName]
+			if !ok {
+				continue
+			}
+			var value string
+			if len(tag) > 1 {
+				value = tag[1]
+			}
+			if _, permitted := allowed[value]; !permitted {
+				reason := fmt.Sprintf("tag_value_not_permitted:'%s','%s'", tagName, value)
+				return newResult(false, reason, nil)
+			}
+		}
+	}
+
+	if len(processedRule.uniqueVals) > 0 {
+		seen := make(map[[2]string]struct{})
+		for _, tag := range event.Tags {
+			if len(tag) < 2 {
+				continue
+			}
+			tagName := normalizeTagName(tag[0], rules.normalizeNames)
+			if _, ok := processedRule.uniqueVals[tagName]; !ok {
+				continue
+			}
+			key := [2]string{tagName, tag[1]}
+			if _, dup := seen[key]; dup {
+				reason := fmt.Sprintf("duplicate_tag_value:'%s','%s'", tagName, tag[1])
+				return newResult(false, reason, nil)
+			}
+			seen[key] = struct{}{}
+		}
+	}
+
+	if len(processedRule.conditionals) > 0 {
+		present := make(map[string]struct{}, len(event.Tags))
+		for _, tag := range event.Tags {
+			if len(tag) > 0 {
+				present[normalizeTagName(tag[0], rules.normalizeNames)] = struct{}{}
+			}
+		}
+
+		for _, cond := range processedRule.conditionals {
+			if _, triggered := present[cond.ifTag]; !triggered {
+				continue
+			}
+			for _, req := range cond.thenRequire {
+				if _, ok := present[req]; !ok {
+					reason := fmt.Sprintf("missing_conditional_tag:'%s',required_by_'%s'", req, cond.ifTag)
+					return newResult(false, reason, nil)
+				}
+			}
+		}
+	}
+
 	return newResult(true, "tags_ok", nil)
 }
+
+// normalizeTagName lowercases and trims name when normalization is enabled.
+func normalizeTagName(name string, normalize bool) string {
+	if !normalize {
+		return name
+	}
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,130 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	threadRateFilterName = "ThreadRateFilter"
+)
+
+type ThreadRateFilter struct {
+	filterBase
+
+	mu     sync.Mutex
+	cfg    *config.ThreadRateFilterConfig
+	kinds  map[int]struct{}
+	counts *lru.LRU[string, *int]
+}
+
+func NewThreadRateFilter(cfg *config.ThreadRateFilterConfig) (*ThreadRateFilter, error) {
+	if !cfg.Enabled {
+		return &ThreadRateFilter{cfg: cfg}, nil
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 65536
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = time.Hour
+	}
+
+	filter := &ThreadRateFilter{
+		cfg:    cfg,
+		kinds:  kinds,
+		counts: lru.NewLRU[string, *int](size, nil, window),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ThreadRateFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(threadRateFilterName, event, meta)
+
+	if !f.cfg.Enabled || f.cfg.MaxPerThread <= 0 {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	root := threadRootID(event.Tags)
+	if root == "" {
+		return newResult(true, "no_thread_root", nil)
+	}
+
+	key := event.PubKey + ":" + root
+
+	f.mu.Lock()
+	allowed, reason := f.evaluateLocked(key)
+	f.mu.Unlock()
+
+	return newResult(allowed, reason, nil)
+}
+
+// evaluateLocked checks and counts a reply to the thread key. The caller must hold f.mu.
+func (f *ThreadRateFilter) evaluateLocked(key string) (bool, string) {
+	count, ok := f.counts.Get(key)
+	if !ok {
+		// The entry is added once so the window starts at the first reply.
+		count = new(int)
+		f.counts.Add(key, count)
+	}
+	if *count >= f.cfg.MaxPerThread {
+		reason := fmt.Sprintf("too_many_replies_to_thread:max_%d", f.cfg.MaxPerThread)
+		return false, reason
+	}
+	*count++
+
+	return true, "thread_rate_ok"
+}
+
+// threadRootID returns the NIP-10 root event ID: the "e" tag marked "root",
+// or, for the deprecated positional convention, the first unmarked "e" tag.
+func threadRootID(tags nostr.Tags) string {
+	var firstUnmarked string
+	for _, tag := range tags {
+		if len(tag) < 2 || tag[0] != "e" {
+			continue
+		}
+		if len(tag) >= 4 {
+			if tag[3] == "root" {
+				return tag[1]
+			}
+			if tag[3] != "" {
+				continue
+			}
+		}
+		if firstUnmarked == "" {
+			firstUnmarked = tag[1]
+		}
+	}
+	return firstUnmarked
+}
+
+// Close releases the filter's caches.
+func (f *ThreadRateFilter) Close() error {
+	purgeCache(f.counts)
+	return nil
+}
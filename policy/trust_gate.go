@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	trustGateName = "TrustGate"
+)
+
+// TrustGate marks events from trusted pubkeys with meta["trusted"] = true.
+// It never rejects. Placed first in a chain, it lets the policy filters
+// after it accept trusted events without checking them; filters enforcing
+// protocol validity or relay-wide state still apply.
+type TrustGate struct {
+	filterBase
+
+	cfg     *config.TrustGateConfig
+	trusted map[string]struct{}
+}
+
+func NewTrustGate(cfg *config.TrustGateConfig) (*TrustGate, error) {
+	warnDisabledWithRules(trustGateName, cfg.Enabled, len(cfg.TrustedPubkeys))
+
+	filter := &TrustGate{
+		cfg:     cfg,
+		trusted: pubkeySet(cfg.TrustedPubkeys),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *TrustGate) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(trustGateName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if _, ok := f.trusted[event.PubKey]; !ok {
+		return newResult(true, "pubkey_not_trusted", nil)
+	}
+	if meta != nil {
+		meta["trusted"] = true
+	}
+	return newResult(true, "pubkey_trusted", nil)
+}
+
+// isTrusted reports whether a TrustGate earlier in the chain marked the
+// event's author as trusted.
+func isTrusted(meta map[string]any) bool {
+	trusted, _ := meta["trusted"].(bool)
+	return trusted
+}
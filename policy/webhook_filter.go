@@ -0,0 +1,181 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2/expirable"
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	webhookFilterName = "WebhookFilter"
+)
+
+// webhookVerdict is the response body expected from the moderation service.
+type webhookVerdict struct {
+	Allow  bool   `json:"allow"`
+	Reason string `json:"reason"`
+}
+
+// WebhookFilter delegates the decision to an external HTTP service. It POSTs
+// the event as JSON to URL and expects {"allow": bool, "reason": string}.
+// Network errors and 5xx responses are retried up to MaxRetries times;
+// verdicts are cached by event id for CacheTTL. When the service cannot be
+// reached, FailOpen decides whether the event is accepted.
+type WebhookFilter struct {
+	filterBase
+
+	cfg      *config.WebhookFilterConfig
+	kinds    map[int]struct{}
+	client   *http.Client
+	timeout  time.Duration
+	verdicts *lru.LRU[string, webhookVerdict]
+}
+
+// NewWebhookFilter builds a WebhookFilter. A nil client uses a default
+// http.Client; per-request timeouts come from HTTPTimeout either way.
+func NewWebhookFilter(cfg *config.WebhookFilterConfig, client *http.Client) (*WebhookFilter, error) {
+	if !cfg.Enabled {
+		return &WebhookFilter{cfg: cfg}, nil
+	}
+	u, err := url.Parse(cfg.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url %q", cfg.URL)
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	kinds := make(map[int]struct{}, len(cfg.Kinds))
+	for _, k := range cfg.Kinds {
+		kinds[k] = struct{}{}
+	}
+
+	size := cfg.CacheSize
+	if size <= 0 {
+		size = 10000
+	}
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 10 * time.Minute
+	}
+	timeout := cfg.HTTPTimeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+
+	filter := &WebhookFilter{
+		cfg:      cfg,
+		kinds:    kinds,
+		client:   client,
+		timeout:  timeout,
+		verdicts: lru.NewLRU[string, webhookVerdict](size, nil, ttl),
+	}
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *WebhookFilter) Match(ctx context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(webhookFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+	if len(f.kinds) > 0 {
+		if _, ok := f.kinds[event.Kind]; !ok {
+			return newResult(true, "kind_not_checked", nil)
+		}
+	}
+
+	verdict, ok := f.verdicts.Get(event.ID)
+	if !ok {
+		var err error
+		verdict, err = f.query(ctx, event)
+		if err != nil {
+			if f.cfg.FailOpen {
+				return newResult(true, "webhook_failed_open", nil)
+			}
+			return newResult(false, "internal_webhook_failed", err)
+		}
+		f.verdicts.Add(event.ID, verdict)
+	}
+
+	if !verdict.Allow {
+		return newResult(false, fmt.Sprintf("webhook_rejected:'%s'", verdict.Reason), nil)
+	}
+	return newResult(true, "webhook_allowed", nil)
+}
+
+// query asks the service for a verdict, retrying transient failures.
+func (f *WebhookFilter) query(ctx context.Context, event *nostr.Event) (webhookVerdict, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return webhookVerdict{}, fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= max(f.cfg.MaxRetries, 0); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return webhookVerdict{}, ctx.Err()
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			}
+		}
+		verdict, retry, err := f.post(ctx, body)
+		if err == nil {
+			return verdict, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return webhookVerdict{}, lastErr
+}
+
+// post sends one request. retry reports whether the failure is transient.
+func (f *WebhookFilter) post(ctx context.Context, body []byte) (verdict webhookVerdict, retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return webhookVerdict{}, false, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return webhookVerdict{}, true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return webhookVerdict{}, resp.StatusCode >= 500, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&verdict); err != nil {
+		return webhookVerdict{}, false, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	return verdict, false, nil
+}
+
+// Close releases the verdict cache.
+func (f *WebhookFilter) Close() error {
+	purgeCache(f.verdicts)
+	return nil
+}
@@ -0,0 +1,98 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	wotFilterName = "WoTFilter"
+)
+
+type WoTFilter struct {
+	filterBase
+
+	mu        sync.RWMutex
+	cfg       *config.WoTFilterConfig
+	reachable map[string]struct{}
+}
+
+func NewWoTFilter(cfg *config.WoTFilterConfig, graph map[string][]string) (*WoTFilter, error) {
+	warnDisabledWithRules(wotFilterName, cfg.Enabled, len(cfg.Anchors))
+	if !cfg.Enabled {
+		return &WoTFilter{cfg: cfg}, nil
+	}
+	if len(cfg.Anchors) == 0 {
+		return nil, errors.New("wot filter enabled but no anchors configured")
+	}
+
+	filter := &WoTFilter{cfg: cfg}
+	filter.UpdateGraph(graph)
+
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+// UpdateGraph replaces the follow graph and recomputes the set of pubkeys
+// reachable from the anchors within MaxHops.
+func (f *WoTFilter) UpdateGraph(graph map[string][]string) {
+	reachable := computeReachable(f.cfg.Anchors, graph, f.cfg.MaxHops)
+
+	f.mu.Lock()
+	f.reachable = reachable
+	f.mu.Unlock()
+}
+
+func (f *WoTFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(wotFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if isTrusted(meta) {
+		return newResult(true, "pubkey_trusted", nil)
+	}
+
+	f.mu.RLock()
+	_, ok := f.reachable[event.PubKey]
+	f.mu.RUnlock()
+
+	if !ok {
+		return newResult(false, "pubkey_not_in_web_of_trust", nil)
+	}
+
+	return newResult(true, "pubkey_in_web_of_trust", nil)
+}
+
+// computeReachable runs a breadth-first search from the anchors. Anchors are
+// at hop 0, their direct follows at hop 1, and so on.
+func computeReachable(anchors []string, graph map[string][]string, maxHops int) map[string]struct{} {
+	reachable := make(map[string]struct{}, len(anchors))
+	frontier := make([]string, 0, len(anchors))
+	for _, pk := range anchors {
+		if _, seen := reachable[pk]; !seen {
+			reachable[pk] = struct{}{}
+			frontier = append(frontier, pk)
+		}
+	}
+
+	for hop := 0; hop < maxHops && len(frontier) > 0; hop++ {
+		var next []string
+		for _, pk := range frontier {
+			for _, follow := range graph[pk] {
+				if _, seen := reachable[follow]; !seen {
+					reachable[follow] = struct{}{}
+					next = append(next, follow)
+				}
+			}
+		}
+		frontier = next
+	}
+
+	return reachable
+}
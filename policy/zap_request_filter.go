@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+
+	"github.com/lessucettes/adresu-kit/config"
+)
+
+const (
+	zapRequestFilterName = "ZapRequestFilter"
+)
+
+// ZapRequestFilter validates the NIP-57 structure of kind-9734 zap requests:
+// a `relays` tag with at least one relay, a positive millisat `amount` within
+// bounds, exactly one `p` tag and at most one `e` tag.
+type ZapRequestFilter struct {
+	filterBase
+
+	cfg *config.ZapRequestFilterConfig
+}
+
+func NewZapRequestFilter(cfg *config.ZapRequestFilterConfig) (*ZapRequestFilter, error) {
+	if cfg.MaxAmount > 0 && cfg.MinAmount > cfg.MaxAmount {
+		return nil, fmt.Errorf("zap request min_amount_msat %d exceeds max_amount_msat %d", cfg.MinAmount, cfg.MaxAmount)
+	}
+
+	filter := &ZapRequestFilter{cfg: cfg}
+	filter.dryRun.Store(cfg.DryRun)
+	return filter, nil
+}
+
+func (f *ZapRequestFilter) Match(_ context.Context, event *nostr.Event, meta map[string]any) (FilterResult, error) {
+	newResult := f.resultFunc(zapRequestFilterName, event, meta)
+
+	if !f.cfg.Enabled {
+		return newResult(true, "filter_disabled", nil)
+	}
+	if event.Kind != nostr.KindZapRequest {
+		return newResult(true, "kind_not_checked", nil)
+	}
+
+	var relays, amount nostr.Tag
+	var pTags, eTags int
+	for _, tag := range event.Tags {
+		if len(tag) == 0 {
+			continue
+		}
+		switch tag[0] {
+		case "relays":
+			relays = tag
+		case "amount":
+			amount = tag
+		case "p":
+			pTags++
+			if len(tag) < 2 || !nostr.IsValidPublicKey(tag[1]) {
+				return newResult(false, "invalid_zap_tag:'p'", nil)
+			}
+		case "e":
+			eTags++
+			if len(tag) < 2 || !nostr.IsValid32ByteHex(tag[1]) {
+				return newResult(false, "invalid_zap_tag:'e'", nil)
+			}
+		}
+	}
+
+	switch {
+	case len(relays) < 2:
+		return newResult(false, "missing_zap_tag:'relays'", nil)
+	case pTags == 0:
+		return newResult(false, "missing_zap_tag:'p'", nil)
+	case pTags > 1:
+		return newResult(false, fmt.Sprintf("invalid_zap_tag:'p',count_%d", pTags), nil)
+	case eTags > 1:
+		return newResult(false, fmt.Sprintf("invalid_zap_tag:'e',count_%d", eTags), nil)
+	case eTags == 0 && f.cfg.RequireEventTag:
+		return newResult(false, "missing_zap_tag:'e'", nil)
+	case len(amount) < 2:
+		return newResult(false, "missing_zap_tag:'amount'", nil)
+	}
+
+	msats, err := strconv.ParseInt(amount[1], 10, 64)
+	if err != nil || msats <= 0 {
+		return newResult(false, "invalid_zap_tag:'amount'", nil)
+	}
+	if msats < f.cfg.MinAmount || (f.cfg.MaxAmount > 0 && msats > f.cfg.MaxAmount) {
+		reason := fmt.Sprintf("invalid_zap_amount:amount_%d,min_%d,max_%d", msats, f.cfg.MinAmount, f.cfg.MaxAmount)
+		return newResult(false, reason, nil)
+	}
+
+	return newResult(true, "zap_request_valid", nil)
+}